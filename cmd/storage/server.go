@@ -30,6 +30,10 @@ func createTables(ctx context.Context, db *sql.DB) error {
 			userId INTEGER NOT NULL,
 			status TEXT,
 			result TEXT,
+			precision TEXT,
+			evaluatedAt TEXT,
+			progress TEXT,
+			errorEstimate TEXT,
 
 			FOREIGN KEY (userId) REFERENCES users (id)
 		);`
@@ -44,6 +48,16 @@ func createTables(ctx context.Context, db *sql.DB) error {
 			FOREIGN KEY (userId) REFERENCES users (id)
 		);`
 
+		constantsTable = `
+		CREATE TABLE IF NOT EXISTS constants(
+			id INTEGER PRIMARY KEY AUTOINCREMENT,
+			name TEXT,
+			value REAL NOT NULL,
+			userId INTEGER NOT NULL,
+
+			FOREIGN KEY (userId) REFERENCES users (id)
+		);`
+
 		computeServersTable = `
 		CREATE TABLE IF NOT EXISTS computes(
 			id INTEGER PRIMARY KEY AUTOINCREMENT,
@@ -58,15 +72,55 @@ func createTables(ctx context.Context, db *sql.DB) error {
 	if _, err := db.Exec(expressionsTable); err != nil {
 		return err
 	}
+	if err := addMissingColumn(db, "expressions", "precision", "TEXT"); err != nil {
+		return err
+	}
+	if err := addMissingColumn(db, "expressions", "evaluatedAt", "TEXT"); err != nil {
+		return err
+	}
+	if err := addMissingColumn(db, "expressions", "progress", "TEXT"); err != nil {
+		return err
+	}
+	if err := addMissingColumn(db, "expressions", "errorEstimate", "TEXT"); err != nil {
+		return err
+	}
 	if _, err := db.Exec(timeoutsTable); err != nil {
 		return err
 	}
+	if _, err := db.Exec(constantsTable); err != nil {
+		return err
+	}
 	if _, err := db.Exec(computeServersTable); err != nil {
 		return err
 	}
 	return nil
 }
 
+// addMissingColumn adds column to table created before the column was added
+func addMissingColumn(db *sql.DB, table, column, columnType string) error {
+	rows, err := db.Query(fmt.Sprintf("PRAGMA table_info(%s)", table))
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			cid, notNull, pk int
+			name, typ        string
+			dflt             sql.NullString
+		)
+		if err := rows.Scan(&cid, &name, &typ, &notNull, &dflt, &pk); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	rows.Close()
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, columnType))
+	return err
+}
+
 func main() {
 	hostPtr := flag.String("host", "http://localhost", "host of server")
 	portPtr := flag.Int("port", 8080, "port of server")
@@ -5,13 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"strconv"
 	"strings"
 	"sync/atomic"
 	"time"
 
 	op "github.com/XJIeI5/calculator/internal/operation"
-	"github.com/XJIeI5/calculator/internal/parser"
 	"github.com/gorilla/mux"
 )
 
@@ -38,6 +36,8 @@ func newComputationServer(maxGoroutines int32, addr string) *computationServer {
 	}
 	r := mux.NewRouter()
 	r.HandleFunc("/exec", cs.handleExec).Methods("POST")
+	r.HandleFunc("/exec_unary", cs.handleExecUnary).Methods("POST")
+	r.HandleFunc("/exec_func", cs.handleExecFunction).Methods("POST")
 	r.HandleFunc("/regist", cs.handleRegist).Methods("POST")
 	r.HandleFunc("/free_process", cs.handleFreeProccesses).Methods("GET")
 	cs.router = r
@@ -56,7 +56,16 @@ func (c *computationServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	c.router.ServeHTTP(w, r)
 }
 
-func (c *computationServer) handleExec(w http.ResponseWriter, r *http.Request) {
+// execRequest is a body of requests for operation execution
+type execRequest[T op.ExecInfo] struct {
+	Info     T   `json:"op_info"`
+	Duration int `json:"duration"`
+}
+
+// execOperation waits for the requested duration, then finds operand by symbol and writes result of calc.
+// amount of parallel executions is limited by maxGoroutines
+func execOperation[T op.ExecInfo, O op.Operand](c *computationServer, w http.ResponseWriter, r *http.Request,
+	calc func(operand O, info T) (op.Number, error)) {
 	if atomic.LoadInt32(&c.currentGoroutines) >= int32(c.maxGoroutines) {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
@@ -64,10 +73,7 @@ func (c *computationServer) handleExec(w http.ResponseWriter, r *http.Request) {
 	atomic.AddInt32(&c.currentGoroutines, 1)
 
 	defer atomic.AddInt32(&c.currentGoroutines, -1)
-	execInfo := struct {
-		op.BinaryOperationInfo `json:"op_info"`
-		Duration               int `json:"duration"`
-	}{}
+	execInfo := execRequest[T]{}
 	err := json.NewDecoder(r.Body).Decode(&execInfo)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
@@ -77,25 +83,49 @@ func (c *computationServer) handleExec(w http.ResponseWriter, r *http.Request) {
 	timer := time.NewTimer(time.Millisecond * time.Duration(execInfo.Duration))
 	select {
 	case <-timer.C:
-		operand := parser.GetOperand(execInfo.Op)
-		if operand == nil {
-			http.Error(w, fmt.Sprintf("operand '%s' doesn't exist", execInfo.Op), http.StatusBadRequest)
+		operand, ok := op.FindOperand(execInfo.Info.Operand())
+		if !ok {
+			http.Error(w, fmt.Sprintf("operand '%s' doesn't exist", execInfo.Info.Operand()), http.StatusBadRequest)
 			return
 		}
-		bin, ok := operand.(op.BinaryOperand)
+		typed, ok := operand.(O)
 		if !ok {
-			http.Error(w, fmt.Sprintf("operand '%s' is not binary", operand.Symbol()), http.StatusBadRequest)
+			http.Error(w, fmt.Sprintf("operand '%s' can't be executed by this request", operand.Symbol()), http.StatusBadRequest)
 			return
 		}
-		res, err := bin.Exec(float64(execInfo.A), float64(execInfo.B))
+		res, err := calc(typed, execInfo.Info)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusBadRequest)
 			return
 		}
-		w.Write([]byte(strconv.FormatFloat(float64(res), 'f', -1, 32)))
+		w.Write([]byte(res))
 	}
 }
 
+func (c *computationServer) handleExec(w http.ResponseWriter, r *http.Request) {
+	execOperation(c, w, r,
+		func(bin op.BinaryOperand, info op.BinaryOperationInfo) (op.Number, error) {
+			return info.Precision.ExecBinary(bin, info.A, info.B)
+		})
+}
+
+func (c *computationServer) handleExecUnary(w http.ResponseWriter, r *http.Request) {
+	execOperation(c, w, r,
+		func(un op.UnaryOperand, info op.UnaryOperationInfo) (op.Number, error) {
+			return info.Precision.ExecUnary(un, info.A)
+		})
+}
+
+func (c *computationServer) handleExecFunction(w http.ResponseWriter, r *http.Request) {
+	execOperation(c, w, r,
+		func(fn op.FunctionOperand, info op.FunctionOperationInfo) (op.Number, error) {
+			if err := op.CheckArity(fn, len(info.Args)); err != nil {
+				return "", err
+			}
+			return info.Precision.ExecFunction(fn, info.Args...)
+		})
+}
+
 func (c *computationServer) handleFreeProccesses(w http.ResponseWriter, r *http.Request) {
 	cur := atomic.LoadInt32(&c.currentGoroutines)
 	w.Write([]byte(fmt.Sprint(c.maxGoroutines - cur)))
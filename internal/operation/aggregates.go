@@ -0,0 +1,174 @@
+package op
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+)
+
+// AggregateOperand is a function of list of numbers: "sum([1, 2, 3])" is "sum(1, 2, 3)".
+// list literal can be written only as arg of aggregate function
+type AggregateOperand interface {
+	FunctionOperand
+	aggregate()
+}
+
+// SUM
+type sum struct{}
+
+func (s sum) math()             {}
+func (s sum) aggregate()        {}
+func (s sum) Symbol() string    { return "sum" }
+func (s sum) Name() string      { return "sum" }
+func (s sum) Arity() (int, int) { return 1, -1 }
+
+func (s sum) Exec(args ...float64) (float64, error) {
+	var res float64
+	for _, arg := range args {
+		res += arg
+	}
+	if math.IsInf(res, 0) || math.IsNaN(res) {
+		return 0, errors.New("sum is out of range")
+	}
+	return res, nil
+}
+
+func (s sum) ExecRat(args ...*big.Rat) (*big.Rat, error) {
+	res := new(big.Rat)
+	for _, arg := range args {
+		res.Add(res, arg)
+	}
+	return res, nil
+}
+
+func (s sum) ExecFloat(args ...*big.Float) (*big.Float, error) {
+	return checkFloat(s.Name(), func() *big.Float {
+		res := newFloat(args[0]).Set(args[0])
+		for _, arg := range args[1:] {
+			res.Add(res, arg)
+		}
+		return res
+	})
+}
+
+// ExecQuantity returns sum in unit of the first arg: sum([1 m, 20 cm]) = 1.2 m
+func (s sum) ExecQuantity(args ...Quantity) (Quantity, error) {
+	values, u, err := sameUnit(s.Name(), args...)
+	if err != nil {
+		return Quantity{}, err
+	}
+	v, err := s.Exec(values...)
+	return withUnit(v, u), err
+}
+
+// AVG
+type avg struct{}
+
+func (a avg) math()             {}
+func (a avg) aggregate()        {}
+func (a avg) Symbol() string    { return "avg" }
+func (a avg) Name() string      { return "average" }
+func (a avg) Arity() (int, int) { return 1, -1 }
+
+func (a avg) Exec(args ...float64) (float64, error) {
+	// values are divided before adding, so sum of big numbers isn't out of range
+	var res float64
+	for _, arg := range args {
+		res += arg / float64(len(args))
+	}
+	return res, nil
+}
+
+func (a avg) ExecRat(args ...*big.Rat) (*big.Rat, error) {
+	res, _ := Sum.ExecRat(args...)
+	return res.Quo(res, new(big.Rat).SetInt64(int64(len(args)))), nil
+}
+
+func (a avg) ExecFloat(args ...*big.Float) (*big.Float, error) {
+	res, err := Sum.ExecFloat(args...)
+	if err != nil {
+		return nil, err
+	}
+	return res.Quo(res, new(big.Float).SetInt64(int64(len(args)))), nil
+}
+
+func (a avg) ExecQuantity(args ...Quantity) (Quantity, error) {
+	values, u, err := sameUnit(a.Name(), args...)
+	if err != nil {
+		return Quantity{}, err
+	}
+	v, _ := a.Exec(values...)
+	return withUnit(v, u), nil
+}
+
+// MEDIAN
+type median struct{}
+
+func (m median) math()             {}
+func (m median) aggregate()        {}
+func (m median) Symbol() string    { return "median" }
+func (m median) Name() string      { return "median" }
+func (m median) Arity() (int, int) { return 1, -1 }
+
+// Exec returns the middle value of sorted args or average of two middle values
+func (m median) Exec(args ...float64) (float64, error) {
+	sorted := append([]float64(nil), args...)
+	sort.Float64s(sorted)
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle], nil
+	}
+	return Avg.Exec(sorted[middle-1], sorted[middle])
+}
+
+func (m median) ExecRat(args ...*big.Rat) (*big.Rat, error) {
+	sorted := append([]*big.Rat(nil), args...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Cmp(sorted[j]) < 0 })
+	middle := len(sorted) / 2
+	if len(sorted)%2 == 1 {
+		return sorted[middle], nil
+	}
+	return Avg.ExecRat(sorted[middle-1], sorted[middle])
+}
+
+func (m median) ExecQuantity(args ...Quantity) (Quantity, error) {
+	values, u, err := sameUnit(m.Name(), args...)
+	if err != nil {
+		return Quantity{}, err
+	}
+	v, _ := m.Exec(values...)
+	return withUnit(v, u), nil
+}
+
+// STDDEV
+type stddev struct{}
+
+func (s stddev) math()             {}
+func (s stddev) aggregate()        {}
+func (s stddev) Symbol() string    { return "stddev" }
+func (s stddev) Name() string      { return "standard deviation" }
+func (s stddev) Arity() (int, int) { return 1, -1 }
+
+// Exec returns population standard deviation: square root of average of squared deviations from average
+func (s stddev) Exec(args ...float64) (float64, error) {
+	mean, _ := Avg.Exec(args...)
+	squares := make([]float64, len(args))
+	for i, arg := range args {
+		squares[i] = (arg - mean) * (arg - mean)
+	}
+	variance, _ := Avg.Exec(squares...)
+	if math.IsInf(variance, 0) {
+		return 0, errors.New("standard deviation is out of range")
+	}
+	return math.Sqrt(variance), nil
+}
+
+func (s stddev) ExecQuantity(args ...Quantity) (Quantity, error) {
+	values, u, err := sameUnit(s.Name(), args...)
+	if err != nil {
+		return Quantity{}, err
+	}
+	v, err := s.Exec(values...)
+	return withUnit(v, u), err
+}
@@ -0,0 +1,84 @@
+package op
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ImaginaryUnit is the name of imaginary unit in expressions: "2 + 3 * i"
+const ImaginaryUnit = "i"
+
+// ComplexBinaryOperand can be calculated with complex numbers
+type ComplexBinaryOperand interface {
+	BinaryOperand
+	ExecComplex(a, b complex128) (complex128, error)
+}
+
+// ComplexUnaryOperand can be calculated with complex numbers
+type ComplexUnaryOperand interface {
+	UnaryOperand
+	ExecComplex(a complex128) (complex128, error)
+}
+
+// ComplexFunctionOperand can be calculated with complex numbers
+type ComplexFunctionOperand interface {
+	FunctionOperand
+	ExecComplex(args ...complex128) (complex128, error)
+}
+
+// ComplexNumber returns number of complex value: "2i", "1-2i", numbers without imaginary part are real: "3"
+func ComplexNumber(c complex128) Number {
+	return Number(formatComplex(c, 'g'))
+}
+
+// IsComplex returns true if number has imaginary part
+func (n Number) IsComplex() bool { return strings.HasSuffix(string(n), ImaginaryUnit) }
+
+// Complex returns number as complex value
+func (n Number) Complex() (complex128, error) {
+	if !n.IsComplex() {
+		v, err := strconv.ParseFloat(string(n), 64)
+		if err != nil && !errors.Is(err, strconv.ErrRange) {
+			r, ratErr := n.Rat()
+			if ratErr != nil {
+				return 0, fmt.Errorf("'%s' is not a number", n)
+			}
+			v, _ = r.Float64()
+		}
+		return complex(v, 0), nil
+	}
+	c, err := strconv.ParseComplex(string(n), 128)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		return 0, fmt.Errorf("'%s' is not a complex number", n)
+	}
+	return c, nil
+}
+
+// formatComplex writes parts of complex number in format of strconv.FormatFloat
+func formatComplex(c complex128, format byte) string {
+	re, im := real(c), imag(c)
+	if im == 0 {
+		return strconv.FormatFloat(re, format, -1, 64)
+	}
+	imPart := strconv.FormatFloat(im, format, -1, 64) + ImaginaryUnit
+	if re == 0 {
+		return imPart
+	}
+	if im > 0 {
+		imPart = "+" + strings.TrimPrefix(imPart, "+")
+	}
+	return strconv.FormatFloat(re, format, -1, 64) + imPart
+}
+
+// checkComplex returns error if part of complex result is NaN
+func checkComplex(name string, c complex128) (complex128, error) {
+	for _, part := range []float64{real(c), imag(c)} {
+		if math.IsNaN(part) {
+			return 0, fmt.Errorf("result of %s is not a number", name)
+		}
+	}
+	return c, nil
+}
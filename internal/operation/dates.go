@@ -0,0 +1,326 @@
+package op
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// date layouts of numbers, all dates are in UTC: "2024-03-01", "2024-03-01T10:30", "2024-03-01T10:30:15.5"
+const (
+	DateLayout     = "2006-01-02"
+	DateTimeLayout = "2006-01-02T15:04:05.999999999"
+)
+
+var dateLayouts = []string{DateLayout, "2006-01-02T15:04", DateTimeLayout}
+
+// DurationUnits are suffixes of duration literals: "1h30m", "30d", "500ms"
+var DurationUnits = map[string]time.Duration{
+	"w":  7 * 24 * time.Hour,
+	"d":  24 * time.Hour,
+	"h":  time.Hour,
+	"m":  time.Minute,
+	"s":  time.Second,
+	"ms": time.Millisecond,
+}
+
+// durationOrder is order of units in formatted duration
+var durationOrder = []string{"d", "h", "m", "s"}
+
+// TimeKind is a kind of value of date and duration arithmetic
+type TimeKind int
+
+const (
+	RealKind TimeKind = iota
+	DateKind
+	DurationKind
+)
+
+var timeKindNames = map[TimeKind]string{
+	RealKind:     "number",
+	DateKind:     "date",
+	DurationKind: "duration",
+}
+
+func (k TimeKind) String() string { return timeKindNames[k] }
+
+// TimeValue is a date, a duration or a real number used with them: "2024-03-01 + 2 * 1d"
+type TimeValue struct {
+	Kind     TimeKind
+	Date     time.Time
+	Duration time.Duration
+	Real     float64
+}
+
+// DateValue, DurationValue and RealValue return TimeValue of the kind
+func DateValue(t time.Time) TimeValue         { return TimeValue{Kind: DateKind, Date: t.UTC()} }
+func DurationValue(d time.Duration) TimeValue { return TimeValue{Kind: DurationKind, Duration: d} }
+func RealValue(v float64) TimeValue           { return TimeValue{Kind: RealKind, Real: v} }
+
+// TimeBinaryOperand can be calculated with dates and durations
+type TimeBinaryOperand interface {
+	BinaryOperand
+	ExecTime(a, b TimeValue) (TimeValue, error)
+}
+
+// TimeUnaryOperand can be calculated with durations
+type TimeUnaryOperand interface {
+	UnaryOperand
+	ExecTime(a TimeValue) (TimeValue, error)
+}
+
+// TimeFunctionOperand can be calculated with dates and durations
+type TimeFunctionOperand interface {
+	FunctionOperand
+	ExecTime(args ...TimeValue) (TimeValue, error)
+}
+
+// ClockOperand is calculated by storage from the time expression was added at,
+// so result of expression doesn't depend on when it is calculated
+type ClockOperand interface {
+	FunctionOperand
+	At(t time.Time) Number
+}
+
+// TimeNumber returns number of value: "2024-03-01", "1h30m" or real number
+func TimeNumber(v TimeValue) Number {
+	switch v.Kind {
+	case DateKind:
+		return Number(formatDate(v.Date))
+	case DurationKind:
+		return Number(FormatDuration(v.Duration))
+	default:
+		return FloatNumber(v.Real)
+	}
+}
+
+// IsDate returns true if number is a date
+func (n Number) IsDate() bool {
+	_, err := parseDate(string(n))
+	return err == nil
+}
+
+// IsDuration returns true if number is a duration
+func (n Number) IsDuration() bool {
+	_, err := ParseDuration(string(n))
+	return err == nil
+}
+
+// Time returns number as date, duration or real number
+func (n Number) Time() (TimeValue, error) {
+	if t, err := parseDate(string(n)); err == nil {
+		return DateValue(t), nil
+	}
+	if d, err := ParseDuration(string(n)); err == nil {
+		return DurationValue(d), nil
+	}
+	// time units are durations: "1 h" is "1h"
+	if n.IsQuantity() && !n.IsComplex() {
+		q, err := n.Quantity()
+		if err != nil {
+			return TimeValue{}, err
+		}
+		if q.Unit.Dimension() != dimDuration {
+			return TimeValue{}, fmt.Errorf("dates and durations can be used only with time units, got %s", q.unitName())
+		}
+		d, err := checkDuration(q.Value * q.Unit.Factor() * float64(time.Second))
+		return DurationValue(d), err
+	}
+	if n.IsComplex() || n.IsInterval() {
+		return TimeValue{}, errors.New("dates and durations can be used only with real numbers")
+	}
+	f, err := Precision{}.parseFloat(n)
+	if err != nil {
+		return TimeValue{}, err
+	}
+	v, _ := f.Float64()
+	return RealValue(v), nil
+}
+
+// DurationQuantity returns duration as quantity in hours if it is whole hours and in seconds otherwise: "2h" is "2 h"
+func DurationQuantity(d time.Duration) Quantity {
+	if d%time.Hour == 0 {
+		return Quantity{Value: float64(d / time.Hour), Unit: Unit{"h": 1}}
+	}
+	return Quantity{Value: d.Seconds(), Unit: Unit{"s": 1}}
+}
+
+// quantityArgs returns args with durations written as quantities, if there are values with units
+// of other dimension than time and no dates: "60 km / 2h" is "60 km / 2 h". otherwise time units
+// are calculated as durations, so "1 h + 30m" is "1h30m"
+func quantityArgs(args []Number) ([]Number, bool) {
+	hasOther := false
+	for _, arg := range args {
+		if arg.IsDate() {
+			return args, false
+		}
+		if arg.IsQuantity() && !arg.IsComplex() && !arg.IsInterval() {
+			if q, err := arg.Quantity(); err == nil && q.Unit.Dimension() != dimDuration {
+				hasOther = true
+			}
+		}
+	}
+	if !hasOther {
+		return args, false
+	}
+	res := make([]Number, len(args))
+	for i, arg := range args {
+		res[i] = arg
+		if d, err := ParseDuration(string(arg)); err == nil {
+			res[i] = QuantityNumber(DurationQuantity(d))
+		}
+	}
+	return res, true
+}
+
+func parseDate(text string) (time.Time, error) {
+	if len(text) < len(DateLayout) {
+		return time.Time{}, fmt.Errorf("'%s' is not a date", text)
+	}
+	for _, layout := range dateLayouts {
+		if t, err := time.Parse(layout, text); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("'%s' is not a date", text)
+}
+
+// formatDate writes date without time if it is midnight: "2024-03-01", "2024-03-01T10:30:00"
+func formatDate(t time.Time) string {
+	if t.Equal(t.Truncate(24 * time.Hour)) {
+		return t.Format(DateLayout)
+	}
+	return t.Format(DateTimeLayout)
+}
+
+// ParseDuration returns duration written by numbers with units: "1h30m", "-1.5d", "500ms"
+func ParseDuration(text string) (time.Duration, error) {
+	rest := text
+	sign := 1.0
+	if strings.HasPrefix(rest, "-") {
+		sign, rest = -1, rest[1:]
+	}
+	if rest == "" {
+		return 0, fmt.Errorf("'%s' is not a duration", text)
+	}
+	var res float64
+	for rest != "" {
+		end := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+		if end <= 0 {
+			return 0, fmt.Errorf("'%s' is not a duration", text)
+		}
+		v, err := strconv.ParseFloat(rest[:end], 64)
+		if err != nil {
+			return 0, fmt.Errorf("'%s' is not a duration", text)
+		}
+		rest = rest[end:]
+		unitEnd := strings.IndexFunc(rest, func(r rune) bool { return r < 'a' || r > 'z' })
+		if unitEnd < 0 {
+			unitEnd = len(rest)
+		}
+		unit, ok := DurationUnits[rest[:unitEnd]]
+		if !ok {
+			return 0, fmt.Errorf("'%s' is not a duration", text)
+		}
+		rest = rest[unitEnd:]
+		res += v * float64(unit)
+	}
+	return checkDuration(sign * res)
+}
+
+// FormatDuration writes duration by days, hours, minutes and seconds: "1d2h30m", "1.5s", "0s"
+func FormatDuration(d time.Duration) string {
+	if d == 0 {
+		return "0s"
+	}
+	var sb strings.Builder
+	if d < 0 {
+		sb.WriteByte('-')
+	}
+	// the least duration is written as positive by uint64
+	rest := uint64(d)
+	if d < 0 {
+		rest = -rest
+	}
+	for _, name := range durationOrder {
+		unit := uint64(DurationUnits[name])
+		if name == "s" {
+			if rest > 0 {
+				sb.WriteString(strconv.FormatFloat(float64(rest)/float64(unit), 'f', -1, 64) + name)
+			}
+			break
+		}
+		if rest >= unit {
+			sb.WriteString(strconv.FormatUint(rest/unit, 10) + name)
+			rest %= unit
+		}
+	}
+	return sb.String()
+}
+
+// checkDuration returns error if duration in nanoseconds is out of range of time.Duration
+func checkDuration(ns float64) (time.Duration, error) {
+	if math.IsNaN(ns) || ns >= math.MaxInt64 || ns < math.MinInt64 {
+		return 0, errors.New("duration is out of range")
+	}
+	return time.Duration(math.Round(ns)), nil
+}
+
+// kindsError returns error of operand which isn't defined for kinds of args
+func kindsError(name string, args ...TimeValue) error {
+	kinds := make([]string, len(args))
+	for i, arg := range args {
+		kinds[i] = arg.Kind.String()
+	}
+	return fmt.Errorf("%s is not defined for %s", name, strings.Join(kinds, " and "))
+}
+
+// compareTimes returns -1, 0 or 1 comparing two dates or two durations
+func compareTimes(name string, a, b TimeValue) (int, error) {
+	switch {
+	case a.Kind == DateKind && b.Kind == DateKind:
+		return a.Date.Compare(b.Date), nil
+	case a.Kind == DurationKind && b.Kind == DurationKind:
+		switch {
+		case a.Duration < b.Duration:
+			return -1, nil
+		case a.Duration > b.Duration:
+			return 1, nil
+		}
+		return 0, nil
+	}
+	return 0, kindsError(name, a, b)
+}
+
+// compareAll returns the arg which is chosen by comparing with others by less
+func compareAll(name string, args []TimeValue, less func(int) bool) (TimeValue, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		c, err := compareTimes(name, arg, res)
+		if err != nil {
+			return TimeValue{}, err
+		}
+		if less(c) {
+			res = arg
+		}
+	}
+	return res, nil
+}
+
+// NOW
+type now struct{}
+
+func (n now) math()             {}
+func (n now) Symbol() string    { return "now" }
+func (n now) Name() string      { return "now" }
+func (n now) Arity() (int, int) { return 0, 0 }
+
+func (n now) Exec(args ...float64) (float64, error) {
+	return 0, errors.New("now is calculated by storage")
+}
+
+// At returns date and time expression was added at
+func (n now) At(t time.Time) Number { return TimeNumber(DateValue(t)) }
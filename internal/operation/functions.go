@@ -0,0 +1,313 @@
+package op
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
+
+// CheckArity returns error if function can't be called with argsCount args
+func CheckArity(f FunctionOperand, argsCount int) error {
+	minArgs, maxArgs := f.Arity()
+	if argsCount < minArgs || (maxArgs >= 0 && argsCount > maxArgs) {
+		if minArgs == maxArgs {
+			return fmt.Errorf("function '%s' takes %d args, got %d", f.Symbol(), minArgs, argsCount)
+		}
+		if maxArgs < 0 {
+			return fmt.Errorf("function '%s' takes at least %d args, got %d", f.Symbol(), minArgs, argsCount)
+		}
+		return fmt.Errorf("function '%s' takes from %d to %d args, got %d", f.Symbol(), minArgs, maxArgs, argsCount)
+	}
+	return nil
+}
+
+// SIN
+type sin struct{}
+
+func (s sin) math()             {}
+func (s sin) Symbol() string    { return "sin" }
+func (s sin) Name() string      { return "sine" }
+func (s sin) Arity() (int, int) { return 1, 1 }
+
+func (s sin) Exec(args ...float64) (float64, error) { return math.Sin(args[0]), nil }
+
+func (s sin) ExecComplex(args ...complex128) (complex128, error) { return cmplx.Sin(args[0]), nil }
+
+// COS
+type cos struct{}
+
+func (c cos) math()             {}
+func (c cos) Symbol() string    { return "cos" }
+func (c cos) Name() string      { return "cosine" }
+func (c cos) Arity() (int, int) { return 1, 1 }
+
+func (c cos) Exec(args ...float64) (float64, error) { return math.Cos(args[0]), nil }
+
+func (c cos) ExecComplex(args ...complex128) (complex128, error) { return cmplx.Cos(args[0]), nil }
+
+// SQRT
+type sqrt struct{}
+
+func (s sqrt) math()             {}
+func (s sqrt) Symbol() string    { return "sqrt" }
+func (s sqrt) Name() string      { return "square root" }
+func (s sqrt) Arity() (int, int) { return 1, 1 }
+
+func (s sqrt) Exec(args ...float64) (float64, error) {
+	if args[0] < 0 {
+		return 0, errors.New("square root of negative number")
+	}
+	return math.Sqrt(args[0]), nil
+}
+
+func (s sqrt) ExecFloat(args ...*big.Float) (*big.Float, error) {
+	if args[0].Sign() < 0 {
+		return nil, errors.New("square root of negative number")
+	}
+	return newFloat(args[0]).Sqrt(args[0]), nil
+}
+
+// ExecComplex returns principal square root, so square root of negative number is imaginary: sqrt(-4) = 2i
+func (s sqrt) ExecComplex(args ...complex128) (complex128, error) { return cmplx.Sqrt(args[0]), nil }
+
+func (s sqrt) ExecInterval(args ...Interval) (Interval, error) {
+	a := args[0]
+	if a.Lo < 0 {
+		return Interval{}, errors.New("square root of interval with negative numbers")
+	}
+	return Interval{Lo: sqrtBounds(a.Lo).Lo, Hi: sqrtBounds(a.Hi).Hi}, nil
+}
+
+// ExecQuantity halves powers of units, so they must be even: sqrt(16 m^2) = 4 m
+func (s sqrt) ExecQuantity(args ...Quantity) (Quantity, error) {
+	a := args[0]
+	root := Unit{}
+	for name, pow := range a.Unit {
+		if pow%2 != 0 {
+			return Quantity{}, fmt.Errorf("square root of %s: powers of units must be even", a.Unit)
+		}
+		root[name] = pow / 2
+	}
+	v, err := s.Exec(a.Value)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return withUnit(v, root), nil
+}
+
+// LOG
+type log struct{}
+
+func (l log) math()          {}
+func (l log) Symbol() string { return "log" }
+func (l log) Name() string   { return "logarithm" }
+
+// Arity of log is 1 for natural logarithm and 2 for logarithm with base: "log(8, 2)"
+func (l log) Arity() (int, int) { return 1, 2 }
+
+func (l log) Exec(args ...float64) (float64, error) {
+	if args[0] <= 0 {
+		return 0, errors.New("logarithm of non-positive number")
+	}
+	if len(args) == 1 {
+		return math.Log(args[0]), nil
+	}
+	if args[1] <= 0 || args[1] == 1 {
+		return 0, errors.New("logarithm base must be positive and not equal to 1")
+	}
+	return math.Log(args[0]) / math.Log(args[1]), nil
+}
+
+// ExecComplex returns principal value of logarithm: log(-1) = 3.14159i
+func (l log) ExecComplex(args ...complex128) (complex128, error) {
+	for _, arg := range args {
+		if arg == 0 {
+			return 0, errors.New("logarithm of zero")
+		}
+	}
+	if len(args) == 1 {
+		return cmplx.Log(args[0]), nil
+	}
+	if args[1] == 1 {
+		return 0, errors.New("logarithm base must not be equal to 1")
+	}
+	return checkComplex(l.Name(), cmplx.Log(args[0])/cmplx.Log(args[1]))
+}
+
+// ExecInterval calculates only natural logarithm, which is increasing
+func (l log) ExecInterval(args ...Interval) (Interval, error) {
+	if len(args) != 1 {
+		return Interval{}, errors.New("only natural logarithm is defined for intervals")
+	}
+	if args[0].Lo <= 0 {
+		return Interval{}, errors.New("logarithm of interval with non-positive numbers")
+	}
+	return widen(math.Log(args[0].Lo), math.Log(args[0].Hi)), nil
+}
+
+// MIN
+type minimum struct{}
+
+func (m minimum) math()             {}
+func (m minimum) aggregate()        {}
+func (m minimum) Symbol() string    { return "min" }
+func (m minimum) Name() string      { return "minimum" }
+func (m minimum) Arity() (int, int) { return 1, -1 }
+
+func (m minimum) Exec(args ...float64) (float64, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		res = math.Min(res, arg)
+	}
+	return res, nil
+}
+
+func (m minimum) ExecRat(args ...*big.Rat) (*big.Rat, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		if arg.Cmp(res) < 0 {
+			res = arg
+		}
+	}
+	return res, nil
+}
+
+func (m minimum) ExecInterval(args ...Interval) (Interval, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		res = Interval{Lo: math.Min(res.Lo, arg.Lo), Hi: math.Min(res.Hi, arg.Hi)}
+	}
+	return res, nil
+}
+
+// ExecQuantity returns minimum in unit of the first arg: min(1 m, 20 cm) = 0.2 m
+func (m minimum) ExecQuantity(args ...Quantity) (Quantity, error) {
+	values, u, err := sameUnit(m.Name(), args...)
+	if err != nil {
+		return Quantity{}, err
+	}
+	v, _ := m.Exec(values...)
+	return withUnit(v, u), nil
+}
+
+// ExecTime returns the earliest date or the shortest duration
+func (m minimum) ExecTime(args ...TimeValue) (TimeValue, error) {
+	return compareAll(m.Name(), args, func(c int) bool { return c < 0 })
+}
+
+func (m minimum) ExecFloat(args ...*big.Float) (*big.Float, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		if arg.Cmp(res) < 0 {
+			res = arg
+		}
+	}
+	return res, nil
+}
+
+// MAX
+type maximum struct{}
+
+func (m maximum) math()             {}
+func (m maximum) aggregate()        {}
+func (m maximum) Symbol() string    { return "max" }
+func (m maximum) Name() string      { return "maximum" }
+func (m maximum) Arity() (int, int) { return 1, -1 }
+
+func (m maximum) Exec(args ...float64) (float64, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		res = math.Max(res, arg)
+	}
+	return res, nil
+}
+
+func (m maximum) ExecRat(args ...*big.Rat) (*big.Rat, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		if arg.Cmp(res) > 0 {
+			res = arg
+		}
+	}
+	return res, nil
+}
+
+func (m maximum) ExecInterval(args ...Interval) (Interval, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		res = Interval{Lo: math.Max(res.Lo, arg.Lo), Hi: math.Max(res.Hi, arg.Hi)}
+	}
+	return res, nil
+}
+
+func (m maximum) ExecQuantity(args ...Quantity) (Quantity, error) {
+	values, u, err := sameUnit(m.Name(), args...)
+	if err != nil {
+		return Quantity{}, err
+	}
+	v, _ := m.Exec(values...)
+	return withUnit(v, u), nil
+}
+
+func (m maximum) ExecTime(args ...TimeValue) (TimeValue, error) {
+	return compareAll(m.Name(), args, func(c int) bool { return c > 0 })
+}
+
+func (m maximum) ExecFloat(args ...*big.Float) (*big.Float, error) {
+	res := args[0]
+	for _, arg := range args[1:] {
+		if arg.Cmp(res) > 0 {
+			res = arg
+		}
+	}
+	return res, nil
+}
+
+// ABS
+type abs struct{}
+
+func (a abs) math()             {}
+func (a abs) Symbol() string    { return "abs" }
+func (a abs) Name() string      { return "absolute value" }
+func (a abs) Arity() (int, int) { return 1, 1 }
+
+func (a abs) Exec(args ...float64) (float64, error) { return math.Abs(args[0]), nil }
+
+func (a abs) ExecRat(args ...*big.Rat) (*big.Rat, error) { return new(big.Rat).Abs(args[0]), nil }
+
+// ExecComplex returns modulus of complex number, it is real
+func (a abs) ExecComplex(args ...complex128) (complex128, error) {
+	return complex(cmplx.Abs(args[0]), 0), nil
+}
+
+func (a abs) ExecInterval(args ...Interval) (Interval, error) {
+	iv := args[0]
+	switch {
+	case iv.Lo >= 0:
+		return iv, nil
+	case iv.Hi <= 0:
+		return Interval{Lo: -iv.Hi, Hi: -iv.Lo}, nil
+	default:
+		return Interval{Lo: 0, Hi: math.Max(-iv.Lo, iv.Hi)}, nil
+	}
+}
+
+func (a abs) ExecQuantity(args ...Quantity) (Quantity, error) {
+	return withUnit(math.Abs(args[0].Value), args[0].Unit), nil
+}
+
+func (a abs) ExecTime(args ...TimeValue) (TimeValue, error) {
+	if args[0].Kind != DurationKind {
+		return TimeValue{}, kindsError(a.Name(), args[0])
+	}
+	if args[0].Duration < 0 {
+		return Neg.ExecTime(args[0])
+	}
+	return args[0], nil
+}
+
+func (a abs) ExecFloat(args ...*big.Float) (*big.Float, error) {
+	return newFloat(args[0]).Abs(args[0]), nil
+}
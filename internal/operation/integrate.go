@@ -0,0 +1,20 @@
+package op
+
+import "errors"
+
+// DefaultIntegrateTolerance is the most error of integral, it is changed by optional arg of integrate:
+// "integrate(sin(x), x, 0, pi, 1e-6)"
+const DefaultIntegrateTolerance = 1e-10
+
+// INTEGRATE finds definite integral of function by variable: "integrate(x^2, x, 0, 1)". it is calculated
+// by storage, which splits interval into parts calculated by different computation servers
+type integrate struct{}
+
+func (i integrate) math()             {}
+func (i integrate) Symbol() string    { return "integrate" }
+func (i integrate) Name() string      { return "integral" }
+func (i integrate) Arity() (int, int) { return 4, 5 }
+
+func (i integrate) Exec(args ...float64) (float64, error) {
+	return 0, errors.New("integrate is calculated by storage")
+}
@@ -0,0 +1,158 @@
+package op
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Interval is a closed range of real numbers [Lo, Hi], which contains unknown exact value.
+// bounds of results are rounded outward, so the exact result is always inside
+type Interval struct {
+	Lo, Hi float64
+}
+
+// IntervalBinaryOperand can be calculated with intervals
+type IntervalBinaryOperand interface {
+	BinaryOperand
+	ExecInterval(a, b Interval) (Interval, error)
+}
+
+// IntervalUnaryOperand can be calculated with intervals
+type IntervalUnaryOperand interface {
+	UnaryOperand
+	ExecInterval(a Interval) (Interval, error)
+}
+
+// IntervalFunctionOperand can be calculated with intervals
+type IntervalFunctionOperand interface {
+	FunctionOperand
+	ExecInterval(args ...Interval) (Interval, error)
+}
+
+// IntervalNumber returns number of interval: "[1.9,2.1]". bound which would be rounded to other
+// float64 while parsing is written in hexadecimal form: "[0x1.6ccccccccccccp+02,6.3]"
+func IntervalNumber(iv Interval) Number {
+	return Number(fmt.Sprintf("[%s,%s]", formatBound(iv.Lo, big.ToNegativeInf), formatBound(iv.Hi, big.ToPositiveInf)))
+}
+
+// formatBound returns bound which is parsed back to v with rounding mode
+func formatBound(v float64, mode big.RoundingMode) string {
+	s := strconv.FormatFloat(v, 'g', -1, 64)
+	if parsed, err := parseBound(s, mode); err == nil && parsed == v {
+		return s
+	}
+	return strconv.FormatFloat(v, 'x', -1, 64)
+}
+
+// IsInterval returns true if number is an interval
+func (n Number) IsInterval() bool { return strings.HasPrefix(string(n), "[") && !n.IsMatrix() }
+
+// Interval returns number as interval, bounds are rounded outward.
+// real number is an interval with equal bounds: "0.1" is the smallest float64 interval which contains 0.1
+func (n Number) Interval() (Interval, error) {
+	if !n.IsInterval() {
+		lo, err := parseBound(string(n), big.ToNegativeInf)
+		if err != nil {
+			return Interval{}, err
+		}
+		hi, err := parseBound(string(n), big.ToPositiveInf)
+		return Interval{Lo: lo, Hi: hi}, err
+	}
+	bounds := strings.TrimSuffix(strings.TrimPrefix(string(n), "["), "]")
+	loText, hiText, ok := strings.Cut(bounds, ",")
+	if !ok || !strings.HasSuffix(string(n), "]") {
+		return Interval{}, fmt.Errorf("'%s' is not an interval", n)
+	}
+	lo, err := parseBound(strings.TrimSpace(loText), big.ToNegativeInf)
+	if err != nil {
+		return Interval{}, err
+	}
+	hi, err := parseBound(strings.TrimSpace(hiText), big.ToPositiveInf)
+	if err != nil {
+		return Interval{}, err
+	}
+	if lo > hi {
+		return Interval{}, fmt.Errorf("lower bound of interval '%s' is greater than upper", n)
+	}
+	return Interval{Lo: lo, Hi: hi}, nil
+}
+
+// parseBound returns decimal or hexadecimal number as float64 rounded by mode
+func parseBound(s string, mode big.RoundingMode) (float64, error) {
+	f, _, err := big.ParseFloat(s, 0, 53, mode)
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a number", s)
+	}
+	v, _ := f.Float64()
+	return v, nil
+}
+
+// formatInterval writes bounds of interval in format of strconv.FormatFloat
+func formatInterval(iv Interval, format byte) string {
+	return fmt.Sprintf("[%s,%s]", strconv.FormatFloat(iv.Lo, format, -1, 64), strconv.FormatFloat(iv.Hi, format, -1, 64))
+}
+
+// Contains returns true if v is inside interval
+func (iv Interval) Contains(v float64) bool { return iv.Lo <= v && v <= iv.Hi }
+
+// IsTrue returns true if interval doesn't contain zero and false if it is zero,
+// other intervals can't be used as conditions
+func (iv Interval) IsTrue() (bool, error) {
+	if !iv.Contains(0) {
+		return true, nil
+	}
+	if iv.Lo == 0 && iv.Hi == 0 {
+		return false, nil
+	}
+	return false, errors.New("interval which contains zero can't be a condition")
+}
+
+// roundedOp is operation on big.Float which result is rounded by mode of z
+type roundedOp func(z *big.Float) *big.Float
+
+// bounds returns the least and the greatest results of ops, rounded outward
+func bounds(name string, ops ...roundedOp) (Interval, error) {
+	res := Interval{Lo: math.Inf(1), Hi: math.Inf(-1)}
+	for _, calc := range ops {
+		lo, err := checkFloat(name, func() *big.Float { return calc(new(big.Float).SetPrec(53).SetMode(big.ToNegativeInf)) })
+		if err != nil {
+			return Interval{}, err
+		}
+		hi, err := checkFloat(name, func() *big.Float { return calc(new(big.Float).SetPrec(53).SetMode(big.ToPositiveInf)) })
+		if err != nil {
+			return Interval{}, err
+		}
+		l, _ := lo.Float64()
+		h, _ := hi.Float64()
+		res.Lo, res.Hi = math.Min(res.Lo, l), math.Max(res.Hi, h)
+	}
+	return res, nil
+}
+
+// widen returns interval with bounds moved outward by one float64 step,
+// it is used for results of math functions which aren't rounded exactly
+func widen(lo, hi float64) Interval {
+	return Interval{Lo: math.Nextafter(lo, math.Inf(-1)), Hi: math.Nextafter(hi, math.Inf(1))}
+}
+
+// sqrtBounds returns interval of square root of v: math.Sqrt is rounded to nearest,
+// so the bound is moved if the exact root is on the other side of it
+func sqrtBounds(v float64) Interval {
+	r := math.Sqrt(v)
+	square := new(big.Float).SetPrec(106).Mul(bigFloat(r), bigFloat(r))
+	switch square.Cmp(bigFloat(v)) {
+	case 1:
+		return Interval{Lo: math.Nextafter(r, math.Inf(-1)), Hi: r}
+	case -1:
+		return Interval{Lo: r, Hi: math.Nextafter(r, math.Inf(1))}
+	default:
+		return Interval{Lo: r, Hi: r}
+	}
+}
+
+// bigFloat returns v as big.Float
+func bigFloat(v float64) *big.Float { return big.NewFloat(v) }
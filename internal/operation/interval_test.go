@@ -0,0 +1,73 @@
+package op_test
+
+import (
+	"math"
+	"math/big"
+	"testing"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+func TestIntervalBounds(t *testing.T) {
+	var p op.Precision
+	res, err := p.ExecBinary(op.Mult, "[1.9,2.1]", "3")
+	compareBounds(t, "[1.9,2.1] * 3", res, err, "5.7", "6.3")
+	res, err = p.ExecBinary(op.Add, "[0.1,0.1]", "[0.2,0.2]")
+	compareBounds(t, "[0.1,0.1] + [0.2,0.2]", res, err, "0.3", "0.3")
+	res, err = p.ExecBinary(op.Sub, "[1,2]", "[1,2]")
+	compareBounds(t, "[1,2] - [1,2]", res, err, "-1", "1")
+	res, err = p.ExecBinary(op.Pow, "[-2,3]", "2")
+	compareBounds(t, "[-2,3] ^ 2", res, err, "0", "9")
+	res, err = p.ExecBinary(op.Pow, "[-3,-2]", "2")
+	compareBounds(t, "[-3,-2] ^ 2", res, err, "4", "9")
+	res, err = p.ExecBinary(op.Pow, "[-2,3]", "3")
+	compareBounds(t, "[-2,3] ^ 3", res, err, "-8", "27")
+	res, err = p.ExecBinary(op.Div, "1", "[1,2]")
+	compareBounds(t, "1 / [1,2]", res, err, "0.5", "1")
+	res, err = p.ExecBinary(op.Div, "1", "[3,3]")
+	compareBounds(t, "1 / [3,3]", res, err, "1/3", "1/3")
+	res, err = p.ExecUnary(op.Neg, "[1,2]")
+	compareBounds(t, "-[1,2]", res, err, "-2", "-1")
+	res, err = p.ExecFunction(op.Sqrt, "[4,9]")
+	compareBounds(t, "sqrt([4,9])", res, err, "2", "3")
+	res, err = p.ExecFunction(op.Min, "[1,2]", "[3,4]")
+	compareBounds(t, "min([1,2], [3,4])", res, err, "1", "2")
+	res, err = p.ExecFunction(op.Max, "[1,2]", "[3,4]")
+	compareBounds(t, "max([1,2], [3,4])", res, err, "3", "4")
+}
+
+func TestIntervalErrors(t *testing.T) {
+	var p op.Precision
+	compareBinary(t, p, op.Div, "1", "[-1,2]", "", true)
+	compareBinary(t, p, op.Div, "1", "[0,2]", "", true)
+	compareBinary(t, p, op.Div, "[2,3]", "[-1,1]", "", true)
+	compareFunction(t, p, op.Sqrt, []op.Number{"[-1,4]"}, "", true)
+}
+
+// compareBounds checks that bounds of interval are rounded outward from exact values
+func compareBounds(t *testing.T, expr string, res op.Number, err error, lo, hi string) {
+	if err != nil {
+		t.Errorf("'%s': error got '%s'", expr, err)
+		return
+	}
+	iv, err := res.Interval()
+	if err != nil {
+		t.Errorf("'%s': result '%s' is not an interval: %s", expr, res, err)
+		return
+	}
+	checkBound(t, expr, iv.Lo, lo, -1)
+	checkBound(t, expr, iv.Hi, hi, 1)
+}
+
+// checkBound checks that bound is rounded outward from exact value by direction. args are rounded outward too,
+// so bound may be two float64 steps away from exact value, but not more
+func checkBound(t *testing.T, expr string, bound float64, exact string, direction int) {
+	r, _ := new(big.Rat).SetString(exact)
+	if cmp := new(big.Rat).SetFloat64(bound).Cmp(r); cmp != 0 && cmp != direction {
+		t.Errorf("'%s': bound %v is not rounded outward from %s", expr, bound, exact)
+	}
+	next := math.Nextafter(math.Nextafter(bound, math.Inf(-direction)), math.Inf(-direction))
+	if cmp := new(big.Rat).SetFloat64(next).Cmp(r); cmp == direction {
+		t.Errorf("'%s': bound %v is further than two steps from %s", expr, bound, exact)
+	}
+}
@@ -0,0 +1,324 @@
+package op
+
+import "math/big"
+
+// BooleanOperand returns true or false, which are calculated as 1 and 0
+type BooleanOperand interface {
+	MathOperand
+	boolean()
+}
+
+// ConditionalOperand doesn't need all of its args: the first one is a condition,
+// which chooses the arg to be the result, other args aren't calculated
+type ConditionalOperand interface {
+	MathOperand
+	// Choose returns index of the result arg, 0 means the condition itself
+	Choose(cond bool) int
+}
+
+// ConditionOperand is a part of conditional expression "cond ? a : b", which is calculated by If
+type ConditionOperand interface {
+	Operand
+	IsThen() bool
+}
+
+// IsTrue returns true if value is not zero
+func IsTrue(v float64) bool { return v != 0 }
+
+// FromBool returns 1 for true and 0 for false
+func FromBool(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// EQUAL
+type equal struct{}
+
+func (e equal) math()          {}
+func (e equal) boolean()       {}
+func (e equal) Symbol() string { return "==" }
+func (e equal) Name() string   { return "equal" }
+
+func (e equal) Exec(a, b float64) (float64, error) { return FromBool(a == b), nil }
+
+func (e equal) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	return floatFromBool(a.Cmp(b) == 0), nil
+}
+
+func (e equal) ExecRat(a, b *big.Rat) (*big.Rat, error) { return ratFromBool(a.Cmp(b) == 0), nil }
+
+func (e equal) ExecComplex(a, b complex128) (complex128, error) {
+	return complex(FromBool(a == b), 0), nil
+}
+
+func (e equal) ExecQuantity(a, b Quantity) (Quantity, error) {
+	values, _, err := sameUnit(e.Name(), a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: FromBool(values[0] == values[1])}, nil
+}
+
+func (e equal) ExecTime(a, b TimeValue) (TimeValue, error) {
+	c, err := compareTimes(e.Name(), a, b)
+	return RealValue(FromBool(c == 0)), err
+}
+
+// NOT EQUAL
+type notEqual struct{}
+
+func (n notEqual) math()          {}
+func (n notEqual) boolean()       {}
+func (n notEqual) Symbol() string { return "!=" }
+func (n notEqual) Name() string   { return "not equal" }
+
+func (n notEqual) Exec(a, b float64) (float64, error) { return FromBool(a != b), nil }
+
+func (n notEqual) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	return floatFromBool(a.Cmp(b) != 0), nil
+}
+
+func (n notEqual) ExecRat(a, b *big.Rat) (*big.Rat, error) { return ratFromBool(a.Cmp(b) != 0), nil }
+
+func (n notEqual) ExecComplex(a, b complex128) (complex128, error) {
+	return complex(FromBool(a != b), 0), nil
+}
+
+func (n notEqual) ExecQuantity(a, b Quantity) (Quantity, error) {
+	values, _, err := sameUnit(n.Name(), a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: FromBool(values[0] != values[1])}, nil
+}
+
+func (n notEqual) ExecTime(a, b TimeValue) (TimeValue, error) {
+	c, err := compareTimes(n.Name(), a, b)
+	return RealValue(FromBool(c != 0)), err
+}
+
+// LESS
+type less struct{}
+
+func (l less) math()          {}
+func (l less) boolean()       {}
+func (l less) Symbol() string { return "<" }
+func (l less) Name() string   { return "less" }
+
+func (l less) Exec(a, b float64) (float64, error) { return FromBool(a < b), nil }
+
+func (l less) ExecFloat(a, b *big.Float) (*big.Float, error) { return floatFromBool(a.Cmp(b) < 0), nil }
+
+func (l less) ExecRat(a, b *big.Rat) (*big.Rat, error) { return ratFromBool(a.Cmp(b) < 0), nil }
+
+func (l less) ExecQuantity(a, b Quantity) (Quantity, error) {
+	values, _, err := sameUnit(l.Name(), a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: FromBool(values[0] < values[1])}, nil
+}
+
+func (l less) ExecTime(a, b TimeValue) (TimeValue, error) {
+	c, err := compareTimes(l.Name(), a, b)
+	return RealValue(FromBool(c < 0)), err
+}
+
+// LESS OR EQUAL
+type lessEqual struct{}
+
+func (l lessEqual) math()          {}
+func (l lessEqual) boolean()       {}
+func (l lessEqual) Symbol() string { return "<=" }
+func (l lessEqual) Name() string   { return "less or equal" }
+
+func (l lessEqual) Exec(a, b float64) (float64, error) { return FromBool(a <= b), nil }
+
+func (l lessEqual) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	return floatFromBool(a.Cmp(b) <= 0), nil
+}
+
+func (l lessEqual) ExecRat(a, b *big.Rat) (*big.Rat, error) { return ratFromBool(a.Cmp(b) <= 0), nil }
+
+func (l lessEqual) ExecQuantity(a, b Quantity) (Quantity, error) {
+	values, _, err := sameUnit(l.Name(), a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: FromBool(values[0] <= values[1])}, nil
+}
+
+func (l lessEqual) ExecTime(a, b TimeValue) (TimeValue, error) {
+	c, err := compareTimes(l.Name(), a, b)
+	return RealValue(FromBool(c <= 0)), err
+}
+
+// GREATER
+type greater struct{}
+
+func (g greater) math()          {}
+func (g greater) boolean()       {}
+func (g greater) Symbol() string { return ">" }
+func (g greater) Name() string   { return "greater" }
+
+func (g greater) Exec(a, b float64) (float64, error) { return FromBool(a > b), nil }
+
+func (g greater) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	return floatFromBool(a.Cmp(b) > 0), nil
+}
+
+func (g greater) ExecRat(a, b *big.Rat) (*big.Rat, error) { return ratFromBool(a.Cmp(b) > 0), nil }
+
+func (g greater) ExecQuantity(a, b Quantity) (Quantity, error) {
+	values, _, err := sameUnit(g.Name(), a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: FromBool(values[0] > values[1])}, nil
+}
+
+func (g greater) ExecTime(a, b TimeValue) (TimeValue, error) {
+	c, err := compareTimes(g.Name(), a, b)
+	return RealValue(FromBool(c > 0)), err
+}
+
+// GREATER OR EQUAL
+type greaterEqual struct{}
+
+func (g greaterEqual) math()          {}
+func (g greaterEqual) boolean()       {}
+func (g greaterEqual) Symbol() string { return ">=" }
+func (g greaterEqual) Name() string   { return "greater or equal" }
+
+func (g greaterEqual) Exec(a, b float64) (float64, error) { return FromBool(a >= b), nil }
+
+func (g greaterEqual) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	return floatFromBool(a.Cmp(b) >= 0), nil
+}
+
+func (g greaterEqual) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	return ratFromBool(a.Cmp(b) >= 0), nil
+}
+
+func (g greaterEqual) ExecQuantity(a, b Quantity) (Quantity, error) {
+	values, _, err := sameUnit(g.Name(), a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return Quantity{Value: FromBool(values[0] >= values[1])}, nil
+}
+
+func (g greaterEqual) ExecTime(a, b TimeValue) (TimeValue, error) {
+	c, err := compareTimes(g.Name(), a, b)
+	return RealValue(FromBool(c >= 0)), err
+}
+
+// AND
+type and struct{}
+
+func (an and) math()          {}
+func (an and) boolean()       {}
+func (an and) Symbol() string { return "&&" }
+func (an and) Name() string   { return "and" }
+
+func (an and) Exec(a, b float64) (float64, error) { return FromBool(IsTrue(a) && IsTrue(b)), nil }
+
+func (an and) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	return ratFromBool(a.Sign() != 0 && b.Sign() != 0), nil
+}
+
+func (an and) ExecComplex(a, b complex128) (complex128, error) {
+	return complex(FromBool(a != 0 && b != 0), 0), nil
+}
+
+// Choose returns false condition at once: "0 && x" is false whatever x is
+func (an and) Choose(cond bool) int {
+	if cond {
+		return 1
+	}
+	return 0
+}
+
+// OR
+type or struct{}
+
+func (o or) math()          {}
+func (o or) boolean()       {}
+func (o or) Symbol() string { return "||" }
+func (o or) Name() string   { return "or" }
+
+func (o or) Exec(a, b float64) (float64, error) { return FromBool(IsTrue(a) || IsTrue(b)), nil }
+
+func (o or) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	return ratFromBool(a.Sign() != 0 || b.Sign() != 0), nil
+}
+
+func (o or) ExecComplex(a, b complex128) (complex128, error) {
+	return complex(FromBool(a != 0 || b != 0), 0), nil
+}
+
+// Choose returns true condition at once: "1 || x" is true whatever x is
+func (o or) Choose(cond bool) int {
+	if cond {
+		return 0
+	}
+	return 1
+}
+
+// NOT
+type not struct{}
+
+func (n not) math()          {}
+func (n not) prefix()        {}
+func (n not) boolean()       {}
+func (n not) Symbol() string { return "not" }
+func (n not) Name() string   { return "not" }
+
+func (n not) Exec(a float64) (float64, error) { return FromBool(!IsTrue(a)), nil }
+
+func (n not) ExecRat(a *big.Rat) (*big.Rat, error) { return ratFromBool(a.Sign() == 0), nil }
+
+func (n not) ExecComplex(a complex128) (complex128, error) { return complex(FromBool(a == 0), 0), nil }
+
+// IF
+type ifElse struct{}
+
+func (i ifElse) math()             {}
+func (i ifElse) Symbol() string    { return "if" }
+func (i ifElse) Name() string      { return "if" }
+func (i ifElse) Arity() (int, int) { return 3, 3 }
+
+func (i ifElse) Exec(args ...float64) (float64, error) {
+	return args[i.Choose(IsTrue(args[0]))], nil
+}
+
+func (i ifElse) ExecRat(args ...*big.Rat) (*big.Rat, error) {
+	return args[i.Choose(args[0].Sign() != 0)], nil
+}
+
+func (i ifElse) ExecComplex(args ...complex128) (complex128, error) {
+	return args[i.Choose(args[0] != 0)], nil
+}
+
+func (i ifElse) Choose(cond bool) int {
+	if cond {
+		return 1
+	}
+	return 2
+}
+
+// THEN
+type then struct{}
+
+func (t then) Symbol() string { return "?" }
+func (t then) Name() string   { return "then" }
+func (t then) IsThen() bool   { return true }
+
+// ELSE
+type orElse struct{}
+
+func (e orElse) Symbol() string { return ":" }
+func (e orElse) Name() string   { return "else" }
+func (e orElse) IsThen() bool   { return false }
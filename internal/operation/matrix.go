@@ -0,0 +1,260 @@
+package op
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Matrix is a matrix of numbers written by rows: "[[1,2],[3,4]]". rows have equal length,
+// vector is a matrix with one row: "[[1,2,3]]"
+type Matrix [][]Number
+
+// MatrixValue is a matrix or a number used with matrix: "2 * [[1,2]]"
+type MatrixValue struct {
+	// Matrix is nil if value is a number
+	Matrix Matrix
+	Scalar Number
+}
+
+// IsScalar returns true if value is a number
+func (v MatrixValue) IsScalar() bool { return v.Matrix == nil }
+
+// MatrixBinaryOperand can be calculated with matrices, elements are calculated with precision p
+type MatrixBinaryOperand interface {
+	BinaryOperand
+	ExecMatrix(p Precision, a, b MatrixValue) (MatrixValue, error)
+}
+
+// MatrixUnaryOperand can be calculated with matrices
+type MatrixUnaryOperand interface {
+	UnaryOperand
+	ExecMatrix(p Precision, a MatrixValue) (MatrixValue, error)
+}
+
+// MatrixFunctionOperand can be calculated with matrices
+type MatrixFunctionOperand interface {
+	FunctionOperand
+	ExecMatrix(p Precision, args ...MatrixValue) (MatrixValue, error)
+}
+
+// MatrixNumber returns number of matrix: "[[1,2],[3,4]]"
+func MatrixNumber(m Matrix) Number {
+	rows := make([]string, len(m))
+	for i, row := range m {
+		elements := make([]string, len(row))
+		for j, el := range row {
+			elements[j] = string(el)
+		}
+		rows[i] = "[" + strings.Join(elements, ",") + "]"
+	}
+	return Number("[" + strings.Join(rows, ",") + "]")
+}
+
+// IsMatrix returns true if number is a matrix
+func (n Number) IsMatrix() bool { return strings.HasPrefix(string(n), "[[") }
+
+// Matrix returns number as matrix
+func (n Number) Matrix() (Matrix, error) {
+	text := string(n)
+	if !n.IsMatrix() || !strings.HasSuffix(text, "]]") {
+		return nil, fmt.Errorf("'%s' is not a matrix", n)
+	}
+	var m Matrix
+	for _, rowText := range strings.Split(text[2:len(text)-2], "],[") {
+		var row []Number
+		for _, el := range strings.Split(rowText, ",") {
+			if el == "" || strings.ContainsAny(el, "[]") {
+				return nil, fmt.Errorf("'%s' is not a matrix", n)
+			}
+			row = append(row, Number(el))
+		}
+		if len(m) > 0 && len(row) != len(m[0]) {
+			return nil, fmt.Errorf("rows of matrix '%s' have different length", n)
+		}
+		m = append(m, row)
+	}
+	return m, nil
+}
+
+// Size returns amount of rows and columns of matrix
+func (m Matrix) Size() (int, int) { return len(m), len(m[0]) }
+
+// Transpose returns matrix with columns as rows
+func (m Matrix) Transpose() Matrix {
+	rows, cols := m.Size()
+	res := make(Matrix, cols)
+	for j := range res {
+		res[j] = make([]Number, rows)
+		for i := range m {
+			res[j][i] = m[i][j]
+		}
+	}
+	return res
+}
+
+// elementOps calculates elements of matrices with precision p, the first error is kept
+// and the next operations aren't calculated
+type elementOps struct {
+	p   Precision
+	err error
+}
+
+func (e *elementOps) binary(o BinaryOperand, a, b Number) Number {
+	if e.err != nil {
+		return ""
+	}
+	res, err := e.p.ExecBinary(o, a, b)
+	e.err = err
+	return res
+}
+
+func (e *elementOps) unary(o UnaryOperand, a Number) Number {
+	if e.err != nil {
+		return ""
+	}
+	res, err := e.p.ExecUnary(o, a)
+	e.err = err
+	return res
+}
+
+// isTrue returns result of boolean operation on elements
+func (e *elementOps) isTrue(n Number) bool {
+	if e.err != nil {
+		return false
+	}
+	res, err := n.IsTrue()
+	e.err = err
+	return res
+}
+
+// elementwise returns matrix of results of calc for every element
+func (e *elementOps) elementwise(m Matrix, calc func(i, j int, el Number) Number) (MatrixValue, error) {
+	res := make(Matrix, len(m))
+	for i, row := range m {
+		res[i] = make([]Number, len(row))
+		for j, el := range row {
+			res[i][j] = calc(i, j, el)
+		}
+	}
+	return MatrixValue{Matrix: res}, e.err
+}
+
+// sameSize returns error if matrices have different sizes or one of args is a number
+func sameSize(name string, a, b MatrixValue) error {
+	if a.IsScalar() || b.IsScalar() {
+		return fmt.Errorf("%s of matrix and number is not defined", name)
+	}
+	aRows, aCols := a.Matrix.Size()
+	bRows, bCols := b.Matrix.Size()
+	if aRows != bRows || aCols != bCols {
+		return fmt.Errorf("%s of matrices %dx%d and %dx%d is not defined", name, aRows, aCols, bRows, bCols)
+	}
+	return nil
+}
+
+// matrixProduct returns product of matrices: element i, j is a sum of products of row i of a and column j of b
+func matrixProduct(p Precision, a, b Matrix) (MatrixValue, error) {
+	aRows, aCols := a.Size()
+	bRows, bCols := b.Size()
+	if aCols != bRows {
+		return MatrixValue{}, fmt.Errorf("product of matrices %dx%d and %dx%d is not defined", aRows, aCols, bRows, bCols)
+	}
+	ops := &elementOps{p: p}
+	res := make(Matrix, aRows)
+	for i := range res {
+		res[i] = make([]Number, bCols)
+	}
+	return ops.elementwise(res, func(i, j int, _ Number) Number {
+		sum := ops.binary(Mult, a[i][0], b[0][j])
+		for k := 1; k < aCols; k++ {
+			sum = ops.binary(Add, sum, ops.binary(Mult, a[i][k], b[k][j]))
+		}
+		return sum
+	})
+}
+
+// determinant is calculated by gaussian elimination, the row with the greatest absolute value
+// is chosen as pivot to keep precision of float numbers
+func determinant(p Precision, m Matrix) (Number, error) {
+	rows, cols := m.Size()
+	if rows != cols {
+		return "", fmt.Errorf("determinant of matrix %dx%d is not defined, matrix must be square", rows, cols)
+	}
+	ops := &elementOps{p: p}
+	a := make(Matrix, rows)
+	for i := range m {
+		a[i] = append([]Number(nil), m[i]...)
+	}
+	abs := func(n Number) Number {
+		if ops.err != nil {
+			return ""
+		}
+		res, err := p.ExecFunction(Abs, n)
+		ops.err = err
+		return res
+	}
+	det := Number("1")
+	for col := 0; col < rows; col++ {
+		pivot := col
+		for r := col + 1; r < rows; r++ {
+			if ops.isTrue(ops.binary(Greater, abs(a[r][col]), abs(a[pivot][col]))) {
+				pivot = r
+			}
+		}
+		if !ops.isTrue(a[pivot][col]) {
+			return "0", ops.err
+		}
+		if pivot != col {
+			a[pivot], a[col] = a[col], a[pivot]
+			det = ops.unary(Neg, det)
+		}
+		det = ops.binary(Mult, det, a[col][col])
+		for r := col + 1; r < rows; r++ {
+			factor := ops.binary(Div, a[r][col], a[col][col])
+			for c := col; c < rows; c++ {
+				a[r][c] = ops.binary(Sub, a[r][c], ops.binary(Mult, factor, a[col][c]))
+			}
+		}
+	}
+	return det, ops.err
+}
+
+// TRANSPOSE
+type transpose struct{}
+
+func (t transpose) math()             {}
+func (t transpose) Symbol() string    { return "transpose" }
+func (t transpose) Name() string      { return "transpose" }
+func (t transpose) Arity() (int, int) { return 1, 1 }
+
+func (t transpose) Exec(args ...float64) (float64, error) {
+	return 0, errors.New("transpose is defined only for matrices")
+}
+
+func (t transpose) ExecMatrix(p Precision, args ...MatrixValue) (MatrixValue, error) {
+	if args[0].IsScalar() {
+		return MatrixValue{}, errors.New("transpose is defined only for matrices")
+	}
+	return MatrixValue{Matrix: args[0].Matrix.Transpose()}, nil
+}
+
+// DET
+type det struct{}
+
+func (d det) math()             {}
+func (d det) Symbol() string    { return "det" }
+func (d det) Name() string      { return "determinant" }
+func (d det) Arity() (int, int) { return 1, 1 }
+
+func (d det) Exec(args ...float64) (float64, error) {
+	return 0, errors.New("determinant is defined only for matrices")
+}
+
+func (d det) ExecMatrix(p Precision, args ...MatrixValue) (MatrixValue, error) {
+	if args[0].IsScalar() {
+		return MatrixValue{}, errors.New("determinant is defined only for matrices")
+	}
+	res, err := determinant(p, args[0].Matrix)
+	return MatrixValue{Scalar: res}, err
+}
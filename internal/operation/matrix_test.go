@@ -0,0 +1,23 @@
+package op_test
+
+import (
+	"testing"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+func TestDeterminant(t *testing.T) {
+	var p op.Precision
+	compareFunction(t, p, op.Det, []op.Number{"[[2]]"}, "2", false)
+	compareFunction(t, p, op.Det, []op.Number{"[[1,2],[3,4]]"}, "-2", false)
+	compareFunction(t, p, op.Det, []op.Number{"[[0,1],[1,0]]"}, "-1", false)
+	compareFunction(t, p, op.Det, []op.Number{"[[1,2],[2,4]]"}, "0", false)
+
+	rational := op.Precision{Kind: op.Rational}
+	compareFunction(t, rational, op.Det, []op.Number{"[[1,2,3],[4,5,6],[7,8,10]]"}, "-3", false)
+	compareFunction(t, rational, op.Det, []op.Number{"[[1/2,1],[1,3]]"}, "1/2", false)
+
+	compareFunction(t, p, op.Det, []op.Number{"[[1,2,3]]"}, "", true)
+	compareFunction(t, p, op.Det, []op.Number{"[[1,2],[3,4],[5,6]]"}, "", true)
+	compareFunction(t, p, op.Det, []op.Number{"2"}, "", true)
+}
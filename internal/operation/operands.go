@@ -1,6 +1,12 @@
 package op
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"math/cmplx"
+)
 
 type Operand interface {
 	Symbol() string
@@ -19,7 +25,7 @@ type BinaryOperand interface {
 
 type UnaryOperand interface {
 	MathOperand
-	Exec(a float64) float64
+	Exec(a float64) (float64, error)
 }
 
 type PostfixOperand interface {
@@ -32,6 +38,14 @@ type PrefixOperand interface {
 	prefix()
 }
 
+// FunctionOperand is called by name with args in parens: "max(1, 2, 3)"
+type FunctionOperand interface {
+	MathOperand
+	// Arity returns min and max amount of args, max is negative if amount is unlimited
+	Arity() (min, max int)
+	Exec(args ...float64) (float64, error)
+}
+
 type OrderOperand interface {
 	Operand
 	IsStart() bool
@@ -46,6 +60,52 @@ func (a add) Name() string   { return "add" }
 
 func (ad add) Exec(a, b float64) (float64, error) { return a + b, nil }
 
+func (ad add) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	return checkFloat(ad.Name(), func() *big.Float { return newFloat(a).Add(a, b) })
+}
+
+func (ad add) ExecRat(a, b *big.Rat) (*big.Rat, error) { return new(big.Rat).Add(a, b), nil }
+
+func (ad add) ExecComplex(a, b complex128) (complex128, error) { return a + b, nil }
+
+func (ad add) ExecInterval(a, b Interval) (Interval, error) {
+	return bounds(ad.Name(),
+		func(z *big.Float) *big.Float { return z.Add(bigFloat(a.Lo), bigFloat(b.Lo)) },
+		func(z *big.Float) *big.Float { return z.Add(bigFloat(a.Hi), bigFloat(b.Hi)) })
+}
+
+// ExecQuantity returns sum in unit of a: 3 m + 20 cm = 3.2 m
+func (ad add) ExecQuantity(a, b Quantity) (Quantity, error) {
+	values, u, err := sameUnit(ad.Name(), a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return withUnit(values[0]+values[1], u), nil
+}
+
+// ExecTime adds duration to date or duration: 2024-03-01 + 30d = 2024-03-31
+func (ad add) ExecTime(a, b TimeValue) (TimeValue, error) {
+	switch {
+	case a.Kind == DateKind && b.Kind == DurationKind:
+		return DateValue(a.Date.Add(b.Duration)), nil
+	case a.Kind == DurationKind && b.Kind == DateKind:
+		return DateValue(b.Date.Add(a.Duration)), nil
+	case a.Kind == DurationKind && b.Kind == DurationKind:
+		d, err := checkDuration(float64(a.Duration) + float64(b.Duration))
+		return DurationValue(d), err
+	}
+	return TimeValue{}, kindsError(ad.Name(), a, b)
+}
+
+// ExecMatrix adds elements of matrices with the same size
+func (ad add) ExecMatrix(p Precision, a, b MatrixValue) (MatrixValue, error) {
+	if err := sameSize(ad.Name(), a, b); err != nil {
+		return MatrixValue{}, err
+	}
+	ops := &elementOps{p: p}
+	return ops.elementwise(a.Matrix, func(i, j int, el Number) Number { return ops.binary(ad, el, b.Matrix[i][j]) })
+}
+
 // SUB
 type sub struct{}
 
@@ -55,6 +115,55 @@ func (s sub) Name() string   { return "sub " }
 
 func (s sub) Exec(a, b float64) (float64, error) { return a - b, nil }
 
+func (s sub) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	return checkFloat(s.Name(), func() *big.Float { return newFloat(a).Sub(a, b) })
+}
+
+func (s sub) ExecRat(a, b *big.Rat) (*big.Rat, error) { return new(big.Rat).Sub(a, b), nil }
+
+func (s sub) ExecComplex(a, b complex128) (complex128, error) { return a - b, nil }
+
+func (s sub) ExecInterval(a, b Interval) (Interval, error) {
+	return bounds(s.Name(),
+		func(z *big.Float) *big.Float { return z.Sub(bigFloat(a.Lo), bigFloat(b.Hi)) },
+		func(z *big.Float) *big.Float { return z.Sub(bigFloat(a.Hi), bigFloat(b.Lo)) })
+}
+
+func (s sub) ExecQuantity(a, b Quantity) (Quantity, error) {
+	values, u, err := sameUnit(s.Name(), a, b)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return withUnit(values[0]-values[1], u), nil
+}
+
+// ExecTime returns duration between dates or subtracts duration: 2024-03-01 - 2024-01-01 = 60d
+func (s sub) ExecTime(a, b TimeValue) (TimeValue, error) {
+	switch {
+	case a.Kind == DateKind && b.Kind == DurationKind:
+		return DateValue(a.Date.Add(-b.Duration)), nil
+	case a.Kind == DateKind && b.Kind == DateKind:
+		// Sub returns the least or the greatest duration if the result is out of range
+		d := a.Date.Sub(b.Date)
+		if d == math.MaxInt64 || d == math.MinInt64 {
+			return TimeValue{}, errors.New("duration is out of range")
+		}
+		return DurationValue(d), nil
+	case a.Kind == DurationKind && b.Kind == DurationKind:
+		d, err := checkDuration(float64(a.Duration) - float64(b.Duration))
+		return DurationValue(d), err
+	}
+	return TimeValue{}, kindsError(s.Name(), a, b)
+}
+
+func (s sub) ExecMatrix(p Precision, a, b MatrixValue) (MatrixValue, error) {
+	if err := sameSize(s.Name(), a, b); err != nil {
+		return MatrixValue{}, err
+	}
+	ops := &elementOps{p: p}
+	return ops.elementwise(a.Matrix, func(i, j int, el Number) Number { return ops.binary(s, el, b.Matrix[i][j]) })
+}
+
 // MULT
 type mult struct{}
 
@@ -64,6 +173,54 @@ func (m mult) Name() string   { return "mult" }
 
 func (m mult) Exec(a, b float64) (float64, error) { return a * b, nil }
 
+func (m mult) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	return checkFloat(m.Name(), func() *big.Float { return newFloat(a).Mul(a, b) })
+}
+
+func (m mult) ExecRat(a, b *big.Rat) (*big.Rat, error) { return new(big.Rat).Mul(a, b), nil }
+
+func (m mult) ExecComplex(a, b complex128) (complex128, error) { return checkComplex(m.Name(), a*b) }
+
+func (m mult) ExecInterval(a, b Interval) (Interval, error) {
+	var ops []roundedOp
+	for _, x := range []float64{a.Lo, a.Hi} {
+		for _, y := range []float64{b.Lo, b.Hi} {
+			ops = append(ops, func(z *big.Float) *big.Float { return z.Mul(bigFloat(x), bigFloat(y)) })
+		}
+	}
+	return bounds(m.Name(), ops...)
+}
+
+// ExecQuantity multiplies values and units: 5 kg * 2 m/s^2 = 10 N
+func (m mult) ExecQuantity(a, b Quantity) (Quantity, error) {
+	return product(a.Value*b.Value, a.Unit, b.Unit, 1), nil
+}
+
+// ExecTime multiplies duration by number: 2 * 1h30m = 3h
+func (m mult) ExecTime(a, b TimeValue) (TimeValue, error) {
+	switch {
+	case a.Kind == DurationKind && b.Kind == RealKind:
+		d, err := checkDuration(float64(a.Duration) * b.Real)
+		return DurationValue(d), err
+	case a.Kind == RealKind && b.Kind == DurationKind:
+		d, err := checkDuration(a.Real * float64(b.Duration))
+		return DurationValue(d), err
+	}
+	return TimeValue{}, kindsError(m.Name(), a, b)
+}
+
+// ExecMatrix returns product of matrices or matrix multiplied by number: [[1,2],[3,4]] * [[1],[1]] = [[3],[7]]
+func (m mult) ExecMatrix(p Precision, a, b MatrixValue) (MatrixValue, error) {
+	ops := &elementOps{p: p}
+	switch {
+	case a.IsScalar():
+		return ops.elementwise(b.Matrix, func(_, _ int, el Number) Number { return ops.binary(m, a.Scalar, el) })
+	case b.IsScalar():
+		return ops.elementwise(a.Matrix, func(_, _ int, el Number) Number { return ops.binary(m, el, b.Scalar) })
+	}
+	return matrixProduct(p, a.Matrix, b.Matrix)
+}
+
 // DIV
 type div struct{}
 
@@ -78,6 +235,484 @@ func (d div) Exec(a, b float64) (float64, error) {
 	return a / b, nil
 }
 
+func (d div) ExecFloat(a, b *big.Float) (*big.Float, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("zero division")
+	}
+	return checkFloat(d.Name(), func() *big.Float { return newFloat(a).Quo(a, b) })
+}
+
+func (d div) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("zero division")
+	}
+	return new(big.Rat).Quo(a, b), nil
+}
+
+func (d div) ExecComplex(a, b complex128) (complex128, error) {
+	if b == 0 {
+		return 0, errors.New("zero division")
+	}
+	return checkComplex(d.Name(), a/b)
+}
+
+func (d div) ExecInterval(a, b Interval) (Interval, error) {
+	if b.Contains(0) {
+		return Interval{}, errors.New("zero division: divisor interval contains zero")
+	}
+	var ops []roundedOp
+	for _, x := range []float64{a.Lo, a.Hi} {
+		for _, y := range []float64{b.Lo, b.Hi} {
+			ops = append(ops, func(z *big.Float) *big.Float { return z.Quo(bigFloat(x), bigFloat(y)) })
+		}
+	}
+	return bounds(d.Name(), ops...)
+}
+
+func (d div) ExecQuantity(a, b Quantity) (Quantity, error) {
+	if b.Value == 0 {
+		return Quantity{}, errors.New("zero division")
+	}
+	return product(a.Value/b.Value, a.Unit, b.Unit, -1), nil
+}
+
+// ExecTime divides duration by number or by duration: 3h / 1h30m = 2
+func (d div) ExecTime(a, b TimeValue) (TimeValue, error) {
+	switch {
+	case a.Kind == DurationKind && b.Kind == RealKind:
+		if b.Real == 0 {
+			return TimeValue{}, errors.New("zero division")
+		}
+		res, err := checkDuration(float64(a.Duration) / b.Real)
+		return DurationValue(res), err
+	case a.Kind == DurationKind && b.Kind == DurationKind:
+		if b.Duration == 0 {
+			return TimeValue{}, errors.New("zero division")
+		}
+		return RealValue(float64(a.Duration) / float64(b.Duration)), nil
+	}
+	return TimeValue{}, kindsError(d.Name(), a, b)
+}
+
+// ExecMatrix divides matrix by number
+func (d div) ExecMatrix(p Precision, a, b MatrixValue) (MatrixValue, error) {
+	if !b.IsScalar() {
+		return MatrixValue{}, errors.New("division by matrix is not defined")
+	}
+	ops := &elementOps{p: p}
+	return ops.elementwise(a.Matrix, func(_, _ int, el Number) Number { return ops.binary(d, el, b.Scalar) })
+}
+
+// FLOOR DIV
+type floorDiv struct{}
+
+func (d floorDiv) math()          {}
+func (d floorDiv) Symbol() string { return "//" }
+func (d floorDiv) Name() string   { return "floor div" }
+
+func (d floorDiv) Exec(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errors.New("zero division")
+	}
+	return math.Floor(a / b), nil
+}
+
+func (d floorDiv) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("zero division")
+	}
+	return new(big.Rat).SetInt(floorRat(new(big.Rat).Quo(a, b))), nil
+}
+
+// MOD
+type mod struct{}
+
+func (m mod) math()          {}
+func (m mod) Symbol() string { return "mod" }
+func (m mod) Name() string   { return "mod" }
+
+// Exec returns remainder of floor division, it has the sign of b: -7 mod 3 = 2
+func (m mod) Exec(a, b float64) (float64, error) {
+	if b == 0 {
+		return 0, errors.New("zero division")
+	}
+	return a - b*math.Floor(a/b), nil
+}
+
+func (m mod) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	if b.Sign() == 0 {
+		return nil, errors.New("zero division")
+	}
+	floor := new(big.Rat).SetInt(floorRat(new(big.Rat).Quo(a, b)))
+	return floor.Sub(a, floor.Mul(b, floor)), nil
+}
+
+// BITWISE AND
+type bitAnd struct{}
+
+func (b bitAnd) math()          {}
+func (b bitAnd) Symbol() string { return "&" }
+func (b bitAnd) Name() string   { return "bitwise and" }
+
+func (ba bitAnd) Exec(a, b float64) (float64, error) {
+	x, y, err := toIntegers(ba, a, b)
+	return float64(x & y), err
+}
+
+func (ba bitAnd) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	x, y, err := ratIntegers(ba, a, b)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(x.And(x, y)), nil
+}
+
+// BITWISE OR
+type bitOr struct{}
+
+func (b bitOr) math()          {}
+func (b bitOr) Symbol() string { return "|" }
+func (b bitOr) Name() string   { return "bitwise or" }
+
+func (bo bitOr) Exec(a, b float64) (float64, error) {
+	x, y, err := toIntegers(bo, a, b)
+	return float64(x | y), err
+}
+
+func (bo bitOr) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	x, y, err := ratIntegers(bo, a, b)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(x.Or(x, y)), nil
+}
+
+// BITWISE XOR
+type bitXor struct{}
+
+func (b bitXor) math()          {}
+func (b bitXor) Symbol() string { return "xor" }
+func (b bitXor) Name() string   { return "bitwise xor" }
+
+func (bx bitXor) Exec(a, b float64) (float64, error) {
+	x, y, err := toIntegers(bx, a, b)
+	return float64(x ^ y), err
+}
+
+func (bx bitXor) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	x, y, err := ratIntegers(bx, a, b)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(x.Xor(x, y)), nil
+}
+
+// SHIFT LEFT
+type shiftLeft struct{}
+
+func (s shiftLeft) math()          {}
+func (s shiftLeft) Symbol() string { return "<<" }
+func (s shiftLeft) Name() string   { return "shift left" }
+
+func (s shiftLeft) Exec(a, b float64) (float64, error) {
+	x, y, err := toShift(s, a, b)
+	if err != nil {
+		return 0, err
+	}
+	if x<<y>>y != x {
+		return 0, fmt.Errorf("%s overflows int64: %d << %d", s.Name(), x, y)
+	}
+	return float64(x << y), nil
+}
+
+func (s shiftLeft) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	x, y, err := ratShift(s, a, b)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(x.Lsh(x, y)), nil
+}
+
+// SHIFT RIGHT
+type shiftRight struct{}
+
+func (s shiftRight) math()          {}
+func (s shiftRight) Symbol() string { return ">>" }
+func (s shiftRight) Name() string   { return "shift right" }
+
+func (s shiftRight) Exec(a, b float64) (float64, error) {
+	x, y, err := toShift(s, a, b)
+	if err != nil {
+		return 0, err
+	}
+	return float64(x >> y), nil
+}
+
+func (s shiftRight) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	x, y, err := ratShift(s, a, b)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Rat).SetInt(x.Rsh(x, y)), nil
+}
+
+// toIntegers returns args of bitwise operand as integers or error if they aren't integers of int64.
+// float64(math.MaxInt64) is 2^63, so the upper bound is excluded
+func toIntegers(o Operand, a, b float64) (int64, int64, error) {
+	for _, v := range []float64{a, b} {
+		if v != math.Trunc(v) || v < math.MinInt64 || v >= 1<<63 {
+			return 0, 0, fmt.Errorf("%s is defined only for integers, got %v", o.Name(), v)
+		}
+	}
+	return int64(a), int64(b), nil
+}
+
+// toShift returns args of shift operand as integers or error if they aren't integers or shift is too big
+func toShift(o Operand, a, b float64) (int64, int64, error) {
+	x, y, err := toIntegers(o, a, b)
+	if err != nil {
+		return 0, 0, err
+	}
+	if y < 0 || y > 63 {
+		return 0, 0, errors.New("shift must be from 0 to 63")
+	}
+	return x, y, nil
+}
+
+// ratIntegers returns args of bitwise operand as big.Int or error if they aren't integers
+func ratIntegers(o Operand, a, b *big.Rat) (*big.Int, *big.Int, error) {
+	for _, v := range []*big.Rat{a, b} {
+		if !v.IsInt() {
+			return nil, nil, fmt.Errorf("%s is defined only for integers, got %s", o.Name(), v.RatString())
+		}
+	}
+	return new(big.Int).Set(a.Num()), new(big.Int).Set(b.Num()), nil
+}
+
+// ratShift returns args of shift operand or error if they aren't integers or shift is too big
+func ratShift(o Operand, a, b *big.Rat) (*big.Int, uint, error) {
+	x, y, err := ratIntegers(o, a, b)
+	if err != nil {
+		return nil, 0, err
+	}
+	if y.Sign() < 0 || y.Cmp(big.NewInt(63)) > 0 {
+		return nil, 0, errors.New("shift must be from 0 to 63")
+	}
+	return x, uint(y.Uint64()), nil
+}
+
+// floorRat returns the greatest integer which is not greater than r
+func floorRat(r *big.Rat) *big.Int {
+	// denominator is positive, so euclidean division rounds down
+	return new(big.Int).Div(r.Num(), r.Denom())
+}
+
+// POW
+type pow struct{}
+
+func (p pow) math()          {}
+func (p pow) Symbol() string { return "^" }
+func (p pow) Name() string   { return "pow" }
+
+func (p pow) Exec(a, b float64) (float64, error) {
+	res := math.Pow(a, b)
+	if math.IsNaN(res) {
+		return 0, errors.New("result of pow is not a real number")
+	}
+	if math.IsInf(res, 0) && a == 0 {
+		return 0, errors.New("zero division")
+	}
+	return res, nil
+}
+
+// ExecRat calculates only integer powers, which are exact: (2/3)^-2 = 9/4
+func (p pow) ExecRat(a, b *big.Rat) (*big.Rat, error) {
+	if !b.IsInt() {
+		return nil, errors.New("only integer power can be calculated exactly")
+	}
+	if b.Num().CmpAbs(big.NewInt(MaxRatPower)) > 0 {
+		return nil, fmt.Errorf("power must be from -%d to %d", MaxRatPower, MaxRatPower)
+	}
+	if a.Sign() == 0 && b.Sign() < 0 {
+		return nil, errors.New("zero division")
+	}
+	exp := new(big.Int).Abs(b.Num())
+	num := new(big.Int).Exp(a.Num(), exp, nil)
+	denom := new(big.Int).Exp(a.Denom(), exp, nil)
+	if b.Sign() < 0 {
+		num, denom = denom, num
+	}
+	return new(big.Rat).SetFrac(num, denom), nil
+}
+
+// ExecComplex returns principal value of power, so it is used for negative numbers: (-8)^(1/3) = 1+1.732i
+func (p pow) ExecComplex(a, b complex128) (complex128, error) {
+	if a == 0 && real(b) < 0 {
+		return 0, errors.New("zero division")
+	}
+	return checkComplex(p.Name(), cmplx.Pow(a, b))
+}
+
+// ExecInterval calculates only integer powers: [-2, 3]^2 = [0, 9]
+func (p pow) ExecInterval(a, b Interval) (Interval, error) {
+	if b.Lo != b.Hi || b.Lo != math.Trunc(b.Lo) || math.Abs(b.Lo) > MaxRatPower {
+		return Interval{}, errors.New("interval can be raised only to integer power")
+	}
+	n := int(math.Abs(b.Lo))
+	power := func(x float64) roundedOp {
+		return func(z *big.Float) *big.Float {
+			res := new(big.Float).SetPrec(z.Prec()).SetMode(z.Mode()).SetInt64(1)
+			for i := 0; i < n; i++ {
+				res.Mul(res, bigFloat(x))
+			}
+			return z.Set(res)
+		}
+	}
+	res, err := bounds(p.Name(), power(a.Lo), power(a.Hi))
+	if err != nil {
+		return Interval{}, err
+	}
+	// even power of interval with zero inside has minimum at zero
+	if n%2 == 0 && a.Contains(0) {
+		res.Lo = 0
+	}
+	if b.Lo < 0 {
+		return Div.ExecInterval(Interval{Lo: 1, Hi: 1}, res)
+	}
+	return res, nil
+}
+
+// ExecQuantity calculates only integer powers without unit: (2 m)^2 = 4 m^2
+func (p pow) ExecQuantity(a, b Quantity) (Quantity, error) {
+	if len(b.Unit) != 0 || b.Value != math.Trunc(b.Value) || math.Abs(b.Value) > MaxRatPower {
+		return Quantity{}, errors.New("unit can be raised only to integer power without unit")
+	}
+	res, err := p.Exec(a.Value, b.Value)
+	if err != nil {
+		return Quantity{}, err
+	}
+	return product(res, Unit{}, a.Unit, int(b.Value)), nil
+}
+
+// NEG
+type neg struct{}
+
+func (n neg) math()          {}
+func (n neg) prefix()        {}
+func (n neg) Symbol() string { return "u-" }
+func (n neg) Name() string   { return "neg" }
+
+func (n neg) Exec(a float64) (float64, error) { return -a, nil }
+
+func (n neg) ExecFloat(a *big.Float) (*big.Float, error) { return newFloat(a).Neg(a), nil }
+
+func (n neg) ExecRat(a *big.Rat) (*big.Rat, error) { return new(big.Rat).Neg(a), nil }
+
+func (n neg) ExecComplex(a complex128) (complex128, error) { return -a, nil }
+
+func (n neg) ExecInterval(a Interval) (Interval, error) { return Interval{Lo: -a.Hi, Hi: -a.Lo}, nil }
+
+func (n neg) ExecQuantity(a Quantity) (Quantity, error) { return withUnit(-a.Value, a.Unit), nil }
+
+func (n neg) ExecTime(a TimeValue) (TimeValue, error) {
+	if a.Kind != DurationKind {
+		return TimeValue{}, kindsError(n.Name(), a)
+	}
+	d, err := checkDuration(-float64(a.Duration))
+	return DurationValue(d), err
+}
+
+func (n neg) ExecMatrix(p Precision, a MatrixValue) (MatrixValue, error) {
+	ops := &elementOps{p: p}
+	return ops.elementwise(a.Matrix, func(_, _ int, el Number) Number { return ops.unary(n, el) })
+}
+
+// POS
+type pos struct{}
+
+func (p pos) math()          {}
+func (p pos) prefix()        {}
+func (p pos) Symbol() string { return "u+" }
+func (p pos) Name() string   { return "pos" }
+
+func (p pos) Exec(a float64) (float64, error) { return a, nil }
+
+func (p pos) ExecFloat(a *big.Float) (*big.Float, error) { return a, nil }
+
+func (p pos) ExecRat(a *big.Rat) (*big.Rat, error) { return a, nil }
+
+func (p pos) ExecComplex(a complex128) (complex128, error) { return a, nil }
+
+func (p pos) ExecInterval(a Interval) (Interval, error) { return a, nil }
+
+func (p pos) ExecQuantity(a Quantity) (Quantity, error) { return a, nil }
+
+func (p pos) ExecTime(a TimeValue) (TimeValue, error) {
+	if a.Kind != DurationKind {
+		return TimeValue{}, kindsError(p.Name(), a)
+	}
+	return a, nil
+}
+
+func (p pos) ExecMatrix(_ Precision, a MatrixValue) (MatrixValue, error) { return a, nil }
+
+// FACT
+type fact struct{}
+
+func (f fact) math()          {}
+func (f fact) postfix()       {}
+func (f fact) Symbol() string { return "!" }
+func (f fact) Name() string   { return "fact" }
+
+func (f fact) Exec(a float64) (float64, error) {
+	if a < 0 || a != math.Trunc(a) {
+		return 0, errors.New("factorial is defined only for non-negative integers")
+	}
+	res := math.Gamma(a + 1)
+	if math.IsInf(res, 0) {
+		return 0, errors.New("factorial is too big")
+	}
+	return math.Round(res), nil
+}
+
+func (f fact) ExecRat(a *big.Rat) (*big.Rat, error) {
+	if a.Sign() < 0 || !a.IsInt() {
+		return nil, errors.New("factorial is defined only for non-negative integers")
+	}
+	if a.Num().Cmp(big.NewInt(MaxRatFactorial)) > 0 {
+		return nil, errors.New("factorial is too big")
+	}
+	return new(big.Rat).SetInt(new(big.Int).MulRange(1, a.Num().Int64())), nil
+}
+
+// PERCENT
+type percent struct{}
+
+func (p percent) math()          {}
+func (p percent) postfix()       {}
+func (p percent) Symbol() string { return "%" }
+func (p percent) Name() string   { return "percent" }
+
+func (p percent) Exec(a float64) (float64, error) { return a / 100, nil }
+
+func (p percent) ExecRat(a *big.Rat) (*big.Rat, error) {
+	return new(big.Rat).Quo(a, big.NewRat(100, 1)), nil
+}
+
+func (p percent) ExecComplex(a complex128) (complex128, error) { return a / 100, nil }
+
+func (p percent) ExecInterval(a Interval) (Interval, error) {
+	return Div.ExecInterval(a, Interval{Lo: 100, Hi: 100})
+}
+
+func (p percent) ExecQuantity(a Quantity) (Quantity, error) {
+	return withUnit(a.Value/100, a.Unit), nil
+}
+
+func (p percent) ExecFloat(a *big.Float) (*big.Float, error) {
+	return checkFloat(p.Name(), func() *big.Float { return newFloat(a).Quo(a, big.NewFloat(100)) })
+}
+
 // OPEN PAREN
 type openParen struct{}
 
@@ -0,0 +1,38 @@
+package op_test
+
+import (
+	"math"
+	"testing"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+func TestShift(t *testing.T) {
+	if v, err := op.ShiftLeft.Exec(1, 62); err != nil || v != 1<<62 {
+		t.Errorf("'1 << 62': value is not %v, got %v (%v)", float64(1<<62), v, err)
+	}
+	if v, err := op.ShiftLeft.Exec(-1, 63); err != nil || v != math.MinInt64 {
+		t.Errorf("'-1 << 63': value is not %v, got %v (%v)", float64(math.MinInt64), v, err)
+	}
+	if v, err := op.ShiftLeft.Exec(1, 63); err == nil {
+		t.Errorf("'1 << 63': expected error, got %v", v)
+	}
+	if v, err := op.ShiftLeft.Exec(3, 62); err == nil {
+		t.Errorf("'3 << 62': expected error, got %v", v)
+	}
+	if v, err := op.ShiftRight.Exec(-8, 1); err != nil || v != -4 {
+		t.Errorf("'-8 >> 1': value is not -4, got %v (%v)", v, err)
+	}
+}
+
+func TestIntegerRange(t *testing.T) {
+	if v, err := op.BitAnd.Exec(math.MinInt64, -1); err != nil || v != math.MinInt64 {
+		t.Errorf("'-2^63 & -1': value is not %v, got %v (%v)", float64(math.MinInt64), v, err)
+	}
+	if v, err := op.BitAnd.Exec(math.Pow(2, 63), 1); err == nil {
+		t.Errorf("'2^63 & 1': expected error, got %v", v)
+	}
+	if v, err := op.BitOr.Exec(0.5, 1); err == nil {
+		t.Errorf("'0.5 | 1': expected error, got %v", v)
+	}
+}
@@ -1,37 +1,229 @@
 package op
 
+import "math"
+
 var (
-	_           Operand
-	OpenParen   = openParen{}
-	ClosedParen = closeParen{}
-	Add         = add{}
-	Sub         = sub{}
-	Mult        = mult{}
-	Div         = div{}
+	_            Operand
+	OpenParen    = openParen{}
+	ClosedParen  = closeParen{}
+	Add          = add{}
+	Sub          = sub{}
+	Mult         = mult{}
+	Div          = div{}
+	Neg          = neg{}
+	Pos          = pos{}
+	Pow          = pow{}
+	FloorDiv     = floorDiv{}
+	Mod          = mod{}
+	BitAnd       = bitAnd{}
+	BitOr        = bitOr{}
+	BitXor       = bitXor{}
+	ShiftLeft    = shiftLeft{}
+	ShiftRight   = shiftRight{}
+	Equal        = equal{}
+	NotEqual     = notEqual{}
+	Less         = less{}
+	LessEqual    = lessEqual{}
+	Greater      = greater{}
+	GreaterEqual = greaterEqual{}
+	And          = and{}
+	Or           = or{}
+	Not          = not{}
+	Then         = then{}
+	Else         = orElse{}
+	Fact         = fact{}
+	Percent      = percent{}
+	Sin          = sin{}
+	Cos          = cos{}
+	Sqrt         = sqrt{}
+	Log          = log{}
+	Min          = minimum{}
+	Max          = maximum{}
+	Abs          = abs{}
+	If           = ifElse{}
+	Now          = now{}
+	Transpose    = transpose{}
+	Det          = det{}
+	Sum          = sum{}
+	Avg          = avg{}
+	Median       = median{}
+	Stddev       = stddev{}
+	Equation     = equation{}
+	Solve        = solve{}
+	Integrate    = integrate{}
+	// UnitMult is multiplication by unit written after value: "3 m". it isn't written in expressions,
+	// parser inserts it with higher priority than Mult, so "60 km / 2 h" is "(60 km) / (2 h)"
+	UnitMult = unitMult{}
+)
+
+var Operands = []Operand{OpenParen, ClosedParen, Add, Sub, Mult, Div, Neg, Pos, Pow, Fact, Percent,
+	FloorDiv, Mod, BitAnd, BitOr, BitXor, ShiftLeft, ShiftRight,
+	Equal, NotEqual, LessEqual, Less, GreaterEqual, Greater, And, Or, Not, Then, Else,
+	Sin, Cos, Sqrt, Log, Min, Max, Abs, If, Now, Transpose, Det,
+	Sum, Avg, Median, Stddev, Equation, Solve, Integrate}
+
+// UnicodeAliases are symbols pasted from documents which can be used instead of operands
+var UnicodeAliases = map[string]Operand{
+	"×": Mult,
+	"·": Mult,
+	"÷": Div,
+	"−": Sub,
+	"√": Sqrt,
+}
+
+// Constants are built-in named numbers
+var Constants = map[string]float64{
+	"pi":  math.Pi,
+	"e":   math.E,
+	"tau": 2 * math.Pi,
+	"inf": math.Inf(1),
+}
+
+// IsConstant returns true if name is built-in constant or imaginary unit
+func IsConstant(name string) bool {
+	_, ok := Constants[name]
+	return ok || name == ImaginaryUnit
+}
+
+// Aliases are alternative symbols of operands, the main symbol is returned by Symbol()
+var Aliases = map[string]Operand{
+	"**":  Pow,
+	"and": And,
+	"or":  Or,
+}
+
+type Associativity int
+
+const (
+	LeftAssoc Associativity = iota
+	RightAssoc
 )
 
-var Operands = []Operand{OpenParen, ClosedParen, Add, Sub, Mult, Div}
+// OperationInfo describes how operand is placed in expression
+type OperationInfo struct {
+	Priority int
+	Assoc    Associativity
+}
+
+// OperationTable has gaps between priorities, so new operands can be placed between existing ones
+var OperationTable = map[Operand]OperationInfo{
+	OpenParen:    {Priority: 0},
+	ClosedParen:  {Priority: 0},
+	Then:         {Priority: 10, Assoc: RightAssoc},
+	Else:         {Priority: 10, Assoc: RightAssoc},
+	Or:           {Priority: 20, Assoc: LeftAssoc},
+	And:          {Priority: 30, Assoc: LeftAssoc},
+	Equation:     {Priority: 40, Assoc: LeftAssoc},
+	Equal:        {Priority: 50, Assoc: LeftAssoc},
+	NotEqual:     {Priority: 50, Assoc: LeftAssoc},
+	Less:         {Priority: 50, Assoc: LeftAssoc},
+	LessEqual:    {Priority: 50, Assoc: LeftAssoc},
+	Greater:      {Priority: 50, Assoc: LeftAssoc},
+	GreaterEqual: {Priority: 50, Assoc: LeftAssoc},
+	BitOr:        {Priority: 60, Assoc: LeftAssoc},
+	BitXor:       {Priority: 70, Assoc: LeftAssoc},
+	BitAnd:       {Priority: 80, Assoc: LeftAssoc},
+	ShiftLeft:    {Priority: 90, Assoc: LeftAssoc},
+	ShiftRight:   {Priority: 90, Assoc: LeftAssoc},
+	Add:          {Priority: 100, Assoc: LeftAssoc},
+	Sub:          {Priority: 100, Assoc: LeftAssoc},
+	Mult:         {Priority: 110, Assoc: LeftAssoc},
+	Div:          {Priority: 110, Assoc: LeftAssoc},
+	FloorDiv:     {Priority: 110, Assoc: LeftAssoc},
+	Mod:          {Priority: 110, Assoc: LeftAssoc},
+	UnitMult:     {Priority: 120, Assoc: LeftAssoc},
+	Neg:          {Priority: 120, Assoc: RightAssoc},
+	Pos:          {Priority: 120, Assoc: RightAssoc},
+	Not:          {Priority: 120, Assoc: RightAssoc},
+	Pow:          {Priority: 130, Assoc: RightAssoc},
+	Fact:         {Priority: 140, Assoc: LeftAssoc},
+	Percent:      {Priority: 140, Assoc: LeftAssoc},
+	// functions are in the table for the root sign, which is written like prefix operand: "√9"
+	Sin:       {Priority: 120, Assoc: RightAssoc},
+	Cos:       {Priority: 120, Assoc: RightAssoc},
+	Sqrt:      {Priority: 120, Assoc: RightAssoc},
+	Log:       {Priority: 120, Assoc: RightAssoc},
+	Min:       {Priority: 120, Assoc: RightAssoc},
+	Max:       {Priority: 120, Assoc: RightAssoc},
+	Abs:       {Priority: 120, Assoc: RightAssoc},
+	Now:       {Priority: 120, Assoc: RightAssoc},
+	Transpose: {Priority: 120, Assoc: RightAssoc},
+	Det:       {Priority: 120, Assoc: RightAssoc},
+	Sum:       {Priority: 120, Assoc: RightAssoc},
+	Avg:       {Priority: 120, Assoc: RightAssoc},
+	Median:    {Priority: 120, Assoc: RightAssoc},
+	Stddev:    {Priority: 120, Assoc: RightAssoc},
+	Solve:     {Priority: 120, Assoc: RightAssoc},
+	Integrate: {Priority: 120, Assoc: RightAssoc},
+}
+
+// PrefixForms maps binary operands to their prefix form,
+// used when the operand stands where a number is expected: "-3", "2 * (-1)", "!flag"
+var PrefixForms = map[Operand]PrefixOperand{
+	Add:  Pos,
+	Sub:  Neg,
+	Fact: Not,
+}
+
+// InfixForms maps operands to their binary form,
+// used when the operand stands between two numbers: "50%" is percent, "7 % 3" is mod
+var InfixForms = map[Operand]BinaryOperand{
+	Percent: Mod,
+}
 
-var OperationPriority = map[Operand]int{
-	OpenParen:   0,
-	ClosedParen: 0,
-	Add:         1,
-	Sub:         1,
-	Mult:        2,
-	Div:         2,
+// ExecInfo describes one operation sent to computation server
+type ExecInfo interface {
+	// Operand returns symbol of executed operand
+	Operand() string
 }
 
+// BinaryOperationInfo, UnaryOperationInfo and FunctionOperationInfo describe args of operation
+// and precision they are calculated with, float64 by default
 type BinaryOperationInfo struct {
-	A  float32 `json:"a"`
-	B  float32 `json:"b"`
-	Op string  `json:"op"`
+	A         Number    `json:"a"`
+	B         Number    `json:"b"`
+	Op        string    `json:"op"`
+	Precision Precision `json:"precision"`
 }
 
-func HaveOperand(symbol string) bool {
+type UnaryOperationInfo struct {
+	A         Number    `json:"a"`
+	Op        string    `json:"op"`
+	Precision Precision `json:"precision"`
+}
+
+type FunctionOperationInfo struct {
+	Args      []Number  `json:"args"`
+	Op        string    `json:"op"`
+	Precision Precision `json:"precision"`
+}
+
+func (i BinaryOperationInfo) Operand() string   { return i.Op }
+func (i UnaryOperationInfo) Operand() string    { return i.Op }
+func (i FunctionOperationInfo) Operand() string { return i.Op }
+
+// FindOperand returns operand by its symbol or alias
+func FindOperand(symbol string) (Operand, bool) {
 	for _, o := range Operands {
 		if o.Symbol() == symbol {
-			return true
+			return o, true
 		}
 	}
-	return false
+	o, ok := Aliases[symbol]
+	return o, ok
+}
+
+// FindFunction returns function by its name
+func FindFunction(name string) (FunctionOperand, bool) {
+	o, ok := FindOperand(name)
+	if !ok {
+		return nil, false
+	}
+	f, ok := o.(FunctionOperand)
+	return f, ok
+}
+
+func HaveOperand(symbol string) bool {
+	_, ok := FindOperand(symbol)
+	return ok
 }
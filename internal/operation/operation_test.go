@@ -0,0 +1,34 @@
+package op_test
+
+import (
+	"strings"
+	"testing"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+func compareBinary(t *testing.T, p op.Precision, oper op.BinaryOperand, a, b, expected op.Number, expectErr bool) {
+	res, err := p.ExecBinary(oper, a, b)
+	checkResult(t, string(a)+" "+oper.Symbol()+" "+string(b), res, err, expected, expectErr)
+}
+
+func compareFunction(t *testing.T, p op.Precision, fn op.FunctionOperand, args []op.Number, expected op.Number, expectErr bool) {
+	res, err := p.ExecFunction(fn, args...)
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = string(arg)
+	}
+	checkResult(t, fn.Symbol()+"("+strings.Join(names, ", ")+")", res, err, expected, expectErr)
+}
+
+func checkResult(t *testing.T, expr string, res op.Number, err error, expected op.Number, expectErr bool) {
+	if err != nil && !expectErr {
+		t.Errorf("'%s': error got '%s'", expr, err)
+	}
+	if err == nil && expectErr {
+		t.Errorf("'%s': expected error, got '%s'", expr, res)
+	}
+	if res != expected {
+		t.Errorf("'%s': value is not '%s', got '%s'", expr, expected, res)
+	}
+}
@@ -0,0 +1,632 @@
+package op
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// PrecisionKind is a type of numbers expression is calculated with
+type PrecisionKind int
+
+const (
+	// Float64 calculates with float64
+	Float64 PrecisionKind = iota
+	// BigFloat calculates with big.Float with configurable amount of mantissa bits
+	BigFloat
+	// Rational calculates exactly with big.Rat
+	Rational
+)
+
+const (
+	// DefaultFloatBits is mantissa bits of big.Float when they aren't set: "float"
+	DefaultFloatBits uint = 256
+	// MaxFloatBits limits mantissa bits of big.Float
+	MaxFloatBits uint = 1 << 16
+)
+
+// Precision describes how numbers of expression are calculated, zero value is float64
+type Precision struct {
+	Kind PrecisionKind
+	// Bits is amount of mantissa bits for BigFloat
+	Bits uint
+}
+
+// ParsePrecision returns precision by its name: "float64", "float", "float:512", "rat"
+func ParsePrecision(name string) (Precision, error) {
+	kind, bits, hasBits := strings.Cut(name, ":")
+	switch {
+	case kind == "float64" && !hasBits:
+		return Precision{Kind: Float64}, nil
+	case kind == "rat" && !hasBits:
+		return Precision{Kind: Rational}, nil
+	case kind == "float" && !hasBits:
+		return Precision{Kind: BigFloat, Bits: DefaultFloatBits}, nil
+	case kind == "float":
+		n, err := strconv.ParseUint(bits, 10, 32)
+		if err != nil || n == 0 || uint(n) > MaxFloatBits {
+			return Precision{}, fmt.Errorf("bits of float precision must be from 1 to %d, got '%s'", MaxFloatBits, bits)
+		}
+		return Precision{Kind: BigFloat, Bits: uint(n)}, nil
+	default:
+		return Precision{}, fmt.Errorf("unknown precision '%s'", name)
+	}
+}
+
+func (p Precision) String() string {
+	switch p.Kind {
+	case BigFloat:
+		return fmt.Sprintf("float:%d", p.Bits)
+	case Rational:
+		return "rat"
+	default:
+		return "float64"
+	}
+}
+
+func (p Precision) MarshalText() ([]byte, error) { return []byte(p.String()), nil }
+
+func (p *Precision) UnmarshalText(text []byte) error {
+	res, err := ParsePrecision(string(text))
+	if err != nil {
+		return err
+	}
+	*p = res
+	return nil
+}
+
+// Number is a decimal string or fraction "1/3" which keeps value without rounding.
+// in json it is written as number if it is possible and as string otherwise
+type Number string
+
+func (n Number) MarshalJSON() ([]byte, error) {
+	if json.Valid([]byte(n)) {
+		if _, err := strconv.ParseFloat(string(n), 64); err == nil || errors.Is(err, strconv.ErrRange) {
+			return []byte(n), nil
+		}
+	}
+	return json.Marshal(string(n))
+}
+
+func (n *Number) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err == nil {
+		*n = Number(s)
+		return nil
+	}
+	var num json.Number
+	if err := json.Unmarshal(data, &num); err != nil {
+		return err
+	}
+	*n = Number(num)
+	return nil
+}
+
+// FloatNumber returns number of float64 value
+func FloatNumber(v float64) Number { return Number(strconv.FormatFloat(v, 'g', -1, 64)) }
+
+// IsTrue returns true if number is not zero
+func (n Number) IsTrue() (bool, error) {
+	if r, ok := new(big.Rat).SetString(string(n)); ok {
+		return r.Sign() != 0, nil
+	}
+	if n.IsInterval() {
+		iv, err := n.Interval()
+		if err != nil {
+			return false, err
+		}
+		return iv.IsTrue()
+	}
+	if n.IsQuantity() {
+		q, err := n.Quantity()
+		return q.Value != 0, err
+	}
+	if n.IsDuration() {
+		d, err := ParseDuration(string(n))
+		return d != 0, err
+	}
+	if n.IsMatrix() {
+		return false, errors.New("matrix can't be a condition")
+	}
+	if n.IsDate() {
+		return false, errors.New("date can't be a condition")
+	}
+	if n.IsComplex() {
+		c, err := n.Complex()
+		return c != 0, err
+	}
+	f, _, err := big.ParseFloat(string(n), 10, 64, big.ToNearestEven)
+	if err != nil {
+		return false, fmt.Errorf("'%s' is not a number", n)
+	}
+	return f.Sign() != 0, nil
+}
+
+// Rat returns number as big.Rat
+func (n Number) Rat() (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(string(n))
+	if !ok {
+		return nil, fmt.Errorf("'%s' is not a rational number", n)
+	}
+	return r, nil
+}
+
+// FloatBinaryOperand can be calculated with big.Float
+type FloatBinaryOperand interface {
+	BinaryOperand
+	ExecFloat(a, b *big.Float) (*big.Float, error)
+}
+
+// FloatUnaryOperand can be calculated with big.Float
+type FloatUnaryOperand interface {
+	UnaryOperand
+	ExecFloat(a *big.Float) (*big.Float, error)
+}
+
+// FloatFunctionOperand can be calculated with big.Float
+type FloatFunctionOperand interface {
+	FunctionOperand
+	ExecFloat(args ...*big.Float) (*big.Float, error)
+}
+
+// RatBinaryOperand can be calculated with big.Rat
+type RatBinaryOperand interface {
+	BinaryOperand
+	ExecRat(a, b *big.Rat) (*big.Rat, error)
+}
+
+// RatUnaryOperand can be calculated with big.Rat
+type RatUnaryOperand interface {
+	UnaryOperand
+	ExecRat(a *big.Rat) (*big.Rat, error)
+}
+
+// RatFunctionOperand can be calculated with big.Rat
+type RatFunctionOperand interface {
+	FunctionOperand
+	ExecRat(args ...*big.Rat) (*big.Rat, error)
+}
+
+// execFuncs are implementations of operand for each type of numbers, nil if there is no implementation
+type execFuncs struct {
+	float64  func([]float64) (float64, error)
+	float    func([]*big.Float) (*big.Float, error)
+	rat      func([]*big.Rat) (*big.Rat, error)
+	complex  func([]complex128) (complex128, error)
+	interval func([]Interval) (Interval, error)
+	quantity func([]Quantity) (Quantity, error)
+	time     func([]TimeValue) (TimeValue, error)
+	matrix   func([]MatrixValue) (MatrixValue, error)
+}
+
+// ExecBinary calculates binary operand with precision p
+func (p Precision) ExecBinary(o BinaryOperand, a, b Number) (Number, error) {
+	funcs := execFuncs{float64: func(args []float64) (float64, error) { return o.Exec(args[0], args[1]) }}
+	if t, ok := o.(FloatBinaryOperand); ok {
+		funcs.float = func(args []*big.Float) (*big.Float, error) { return t.ExecFloat(args[0], args[1]) }
+	}
+	if t, ok := o.(RatBinaryOperand); ok {
+		funcs.rat = func(args []*big.Rat) (*big.Rat, error) { return t.ExecRat(args[0], args[1]) }
+	}
+	if t, ok := o.(ComplexBinaryOperand); ok {
+		funcs.complex = func(args []complex128) (complex128, error) { return t.ExecComplex(args[0], args[1]) }
+	}
+	if t, ok := o.(IntervalBinaryOperand); ok {
+		funcs.interval = func(args []Interval) (Interval, error) { return t.ExecInterval(args[0], args[1]) }
+	}
+	if t, ok := o.(QuantityBinaryOperand); ok {
+		funcs.quantity = func(args []Quantity) (Quantity, error) { return t.ExecQuantity(args[0], args[1]) }
+	}
+	if t, ok := o.(TimeBinaryOperand); ok {
+		funcs.time = func(args []TimeValue) (TimeValue, error) { return t.ExecTime(args[0], args[1]) }
+	}
+	if t, ok := o.(MatrixBinaryOperand); ok {
+		funcs.matrix = func(args []MatrixValue) (MatrixValue, error) { return t.ExecMatrix(p, args[0], args[1]) }
+	}
+	return p.exec(o, []Number{a, b}, funcs)
+}
+
+// ExecUnary calculates unary operand with precision p
+func (p Precision) ExecUnary(o UnaryOperand, a Number) (Number, error) {
+	funcs := execFuncs{float64: func(args []float64) (float64, error) { return o.Exec(args[0]) }}
+	if t, ok := o.(FloatUnaryOperand); ok {
+		funcs.float = func(args []*big.Float) (*big.Float, error) { return t.ExecFloat(args[0]) }
+	}
+	if t, ok := o.(RatUnaryOperand); ok {
+		funcs.rat = func(args []*big.Rat) (*big.Rat, error) { return t.ExecRat(args[0]) }
+	}
+	if t, ok := o.(ComplexUnaryOperand); ok {
+		funcs.complex = func(args []complex128) (complex128, error) { return t.ExecComplex(args[0]) }
+	}
+	if t, ok := o.(IntervalUnaryOperand); ok {
+		funcs.interval = func(args []Interval) (Interval, error) { return t.ExecInterval(args[0]) }
+	}
+	if t, ok := o.(QuantityUnaryOperand); ok {
+		funcs.quantity = func(args []Quantity) (Quantity, error) { return t.ExecQuantity(args[0]) }
+	}
+	if t, ok := o.(TimeUnaryOperand); ok {
+		funcs.time = func(args []TimeValue) (TimeValue, error) { return t.ExecTime(args[0]) }
+	}
+	if t, ok := o.(MatrixUnaryOperand); ok {
+		funcs.matrix = func(args []MatrixValue) (MatrixValue, error) { return t.ExecMatrix(p, args[0]) }
+	}
+	return p.exec(o, []Number{a}, funcs)
+}
+
+// ExecFunction calculates function with precision p
+func (p Precision) ExecFunction(o FunctionOperand, args ...Number) (Number, error) {
+	funcs := execFuncs{float64: func(args []float64) (float64, error) { return o.Exec(args...) }}
+	if t, ok := o.(FloatFunctionOperand); ok {
+		funcs.float = func(args []*big.Float) (*big.Float, error) { return t.ExecFloat(args...) }
+	}
+	if t, ok := o.(RatFunctionOperand); ok {
+		funcs.rat = func(args []*big.Rat) (*big.Rat, error) { return t.ExecRat(args...) }
+	}
+	if t, ok := o.(ComplexFunctionOperand); ok {
+		funcs.complex = func(args []complex128) (complex128, error) { return t.ExecComplex(args...) }
+	}
+	if t, ok := o.(IntervalFunctionOperand); ok {
+		funcs.interval = func(args []Interval) (Interval, error) { return t.ExecInterval(args...) }
+	}
+	if t, ok := o.(QuantityFunctionOperand); ok {
+		funcs.quantity = func(args []Quantity) (Quantity, error) { return t.ExecQuantity(args...) }
+	}
+	if t, ok := o.(TimeFunctionOperand); ok {
+		funcs.time = func(args []TimeValue) (TimeValue, error) { return t.ExecTime(args...) }
+	}
+	if t, ok := o.(MatrixFunctionOperand); ok {
+		funcs.matrix = func(args []MatrixValue) (MatrixValue, error) { return t.ExecMatrix(p, args...) }
+	}
+	return p.exec(o, args, funcs)
+}
+
+// exec parses args with precision p and calculates them by exec function of this precision.
+// operands which can't be calculated with big.Float are calculated with float64,
+// but operands which can't be calculated with big.Rat return error: the result wouldn't be exact
+func (p Precision) exec(o Operand, args []Number, funcs execFuncs) (Number, error) {
+	for _, arg := range args {
+		if arg.IsMatrix() {
+			return p.execMatrix(o, args, funcs)
+		}
+	}
+	for _, arg := range args {
+		if arg.IsDate() || arg.IsDuration() {
+			if quantities, ok := quantityArgs(args); ok {
+				return p.execQuantity(o, quantities, funcs)
+			}
+			return p.execTime(o, args, funcs)
+		}
+	}
+	for _, arg := range args {
+		if arg.IsQuantity() {
+			return p.execQuantity(o, args, funcs)
+		}
+	}
+	for _, arg := range args {
+		if arg.IsInterval() {
+			return p.execInterval(o, args, funcs)
+		}
+	}
+	for _, arg := range args {
+		if arg.IsComplex() {
+			return p.execComplex(o, args, funcs)
+		}
+	}
+	switch {
+	case p.Kind == Rational:
+		if funcs.rat == nil {
+			return "", fmt.Errorf("%s can't be calculated exactly", o.Name())
+		}
+		rats := make([]*big.Rat, len(args))
+		for i, arg := range args {
+			r, err := arg.Rat()
+			if err != nil {
+				return "", err
+			}
+			rats[i] = r
+		}
+		res, err := funcs.rat(rats)
+		if err != nil {
+			return "", err
+		}
+		return Number(res.RatString()), nil
+	case p.Kind == BigFloat && funcs.float != nil:
+		floats := make([]*big.Float, len(args))
+		for i, arg := range args {
+			f, err := p.parseFloat(arg)
+			if err != nil {
+				return "", err
+			}
+			floats[i] = f
+		}
+		res, err := funcs.float(floats)
+		if err != nil {
+			return "", err
+		}
+		return Number(res.SetPrec(p.Bits).Text('g', -1)), nil
+	case p.Kind == BigFloat && funcs.rat != nil:
+		// big.Float is a binary fraction, so it is converted to big.Rat exactly
+		rats := make([]*big.Rat, len(args))
+		for i, arg := range args {
+			f, err := p.parseFloat(arg)
+			if err != nil {
+				return "", err
+			}
+			if rats[i], _ = f.Rat(nil); rats[i] == nil {
+				return "", fmt.Errorf("%s is not defined for infinity", o.Name())
+			}
+		}
+		res, err := funcs.rat(rats)
+		if err != nil {
+			return "", err
+		}
+		return Number(new(big.Float).SetPrec(p.Bits).SetRat(res).Text('g', -1)), nil
+	case p.Kind == BigFloat:
+		// float64 result would be written with digits it doesn't have
+		return "", fmt.Errorf("%s can't be calculated with %s precision, use float64", o.Name(), p)
+	}
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		f, err := p.parseFloat(arg)
+		if err != nil {
+			return "", err
+		}
+		values[i], _ = f.Float64()
+	}
+	res, err := funcs.float64(values)
+	if err != nil {
+		// operands which aren't defined for real args can give complex result: sqrt(-4) = 2i
+		if p.Kind == Float64 && funcs.complex != nil {
+			if c, complexErr := p.execComplex(o, args, funcs); complexErr == nil {
+				return c, nil
+			}
+		}
+		return "", err
+	}
+	return FloatNumber(res), nil
+}
+
+// execComplex calculates operand with complex numbers, they have only float64 precision
+func (p Precision) execComplex(o Operand, args []Number, funcs execFuncs) (Number, error) {
+	if p.Kind != Float64 {
+		return "", errors.New("complex numbers can be calculated only with float64 precision")
+	}
+	if funcs.complex == nil {
+		return "", fmt.Errorf("%s is not defined for complex numbers", o.Name())
+	}
+	values := make([]complex128, len(args))
+	for i, arg := range args {
+		c, err := arg.Complex()
+		if err != nil {
+			return "", err
+		}
+		values[i] = c
+	}
+	res, err := funcs.complex(values)
+	if err != nil {
+		return "", err
+	}
+	return ComplexNumber(res), nil
+}
+
+// execInterval calculates operand with intervals, their bounds have float64 precision
+func (p Precision) execInterval(o Operand, args []Number, funcs execFuncs) (Number, error) {
+	if p.Kind != Float64 {
+		return "", errors.New("intervals can be calculated only with float64 precision")
+	}
+	if funcs.interval == nil {
+		return "", fmt.Errorf("%s is not defined for intervals", o.Name())
+	}
+	values := make([]Interval, len(args))
+	for i, arg := range args {
+		if arg.IsComplex() {
+			return "", errors.New("complex numbers can't be used with intervals")
+		}
+		iv, err := arg.Interval()
+		if err != nil {
+			return "", err
+		}
+		values[i] = iv
+	}
+	res, err := funcs.interval(values)
+	if err != nil {
+		return "", err
+	}
+	return IntervalNumber(res), nil
+}
+
+// execMatrix calculates operand with matrices, their elements are calculated with precision p
+func (p Precision) execMatrix(o Operand, args []Number, funcs execFuncs) (Number, error) {
+	if funcs.matrix == nil {
+		return "", fmt.Errorf("%s is not defined for matrices", o.Name())
+	}
+	values := make([]MatrixValue, len(args))
+	for i, arg := range args {
+		if !arg.IsMatrix() {
+			values[i] = MatrixValue{Scalar: arg}
+			continue
+		}
+		m, err := arg.Matrix()
+		if err != nil {
+			return "", err
+		}
+		values[i] = MatrixValue{Matrix: m}
+	}
+	res, err := funcs.matrix(values)
+	if err != nil {
+		return "", err
+	}
+	if res.IsScalar() {
+		return res.Scalar, nil
+	}
+	return MatrixNumber(res.Matrix), nil
+}
+
+// execTime calculates operand with dates and durations, they are exact with any precision
+func (p Precision) execTime(o Operand, args []Number, funcs execFuncs) (Number, error) {
+	if funcs.time == nil {
+		return "", fmt.Errorf("%s is not defined for dates and durations", o.Name())
+	}
+	values := make([]TimeValue, len(args))
+	for i, arg := range args {
+		v, err := arg.Time()
+		if err != nil {
+			return "", err
+		}
+		values[i] = v
+	}
+	res, err := funcs.time(values)
+	if err != nil {
+		return "", err
+	}
+	return TimeNumber(res), nil
+}
+
+// execQuantity calculates operand with values with units, values have float64 precision
+func (p Precision) execQuantity(o Operand, args []Number, funcs execFuncs) (Number, error) {
+	if p.Kind != Float64 {
+		return "", errors.New("units can be used only with float64 precision")
+	}
+	if funcs.quantity == nil {
+		return "", fmt.Errorf("%s is not defined for values with units", o.Name())
+	}
+	values := make([]Quantity, len(args))
+	for i, arg := range args {
+		if arg.IsComplex() || arg.IsInterval() {
+			return "", errors.New("units can't be used with complex numbers and intervals")
+		}
+		q, err := arg.Quantity()
+		if err != nil {
+			return "", err
+		}
+		values[i] = q
+	}
+	res, err := funcs.quantity(values)
+	if err != nil {
+		return "", err
+	}
+	return QuantityNumber(res), nil
+}
+
+// parseFloat returns number as big.Float with mantissa bits of precision, float64 has 53 bits
+func (p Precision) parseFloat(n Number) (*big.Float, error) {
+	bits := uint(53)
+	if p.Kind == BigFloat {
+		bits = p.Bits
+	}
+	if r, ok := new(big.Rat).SetString(string(n)); ok {
+		return new(big.Float).SetPrec(bits).SetRat(r), nil
+	}
+	f, _, err := big.ParseFloat(string(n), 10, bits, big.ToNearestEven)
+	if err != nil {
+		return nil, fmt.Errorf("'%s' is not a number", n)
+	}
+	return f, nil
+}
+
+const (
+	// MaxRatPower limits integer power calculated with big.Rat
+	MaxRatPower = 10000
+	// MaxRatFactorial limits factorial calculated with big.Rat
+	MaxRatFactorial = 10000
+)
+
+// RatDigits is amount of fraction digits of rational number which has no exact decimal form: 1/3
+const RatDigits = 30
+
+// Decimal returns number as decimal string without exponent. float numbers are written
+// by the least amount of digits which keeps the value
+func (p Precision) Decimal(n Number) (string, error) {
+	if n.IsMatrix() {
+		m, err := n.Matrix()
+		if err != nil {
+			return "", err
+		}
+		for _, row := range m {
+			for j, el := range row {
+				d, err := p.Decimal(el)
+				if err != nil {
+					return "", err
+				}
+				row[j] = Number(d)
+			}
+		}
+		return string(MatrixNumber(m)), nil
+	}
+	if n.IsDate() || n.IsDuration() {
+		return string(n), nil
+	}
+	if n.IsQuantity() {
+		q, err := n.Quantity()
+		return formatQuantity(q, 'f'), err
+	}
+	if n.IsInterval() {
+		iv, err := n.Interval()
+		return formatInterval(iv, 'f'), err
+	}
+	if n.IsComplex() {
+		c, err := n.Complex()
+		return formatComplex(c, 'f'), err
+	}
+	if p.Kind == Rational {
+		r, err := n.Rat()
+		if err != nil {
+			return "", err
+		}
+		return ratDecimal(r), nil
+	}
+	f, err := p.parseFloat(n)
+	if err != nil {
+		return "", err
+	}
+	if p.Kind == Float64 {
+		v, _ := f.Float64()
+		return strconv.FormatFloat(v, 'f', -1, 64), nil
+	}
+	return f.Text('f', -1), nil
+}
+
+// ratDecimal returns exact decimal form of r if it exists, otherwise r is rounded to RatDigits digits
+func ratDecimal(r *big.Rat) string {
+	denom := new(big.Int).Set(r.Denom())
+	digits := 0
+	for _, factor := range []int64{2, 5} {
+		f := big.NewInt(factor)
+		n := 0
+		for new(big.Int).Mod(denom, f).Sign() == 0 {
+			denom.Quo(denom, f)
+			n++
+		}
+		digits = max(digits, n)
+	}
+	if denom.Cmp(big.NewInt(1)) != 0 {
+		digits = RatDigits
+	}
+	return r.FloatString(digits)
+}
+
+// newFloat returns big.Float with the precision of a
+func newFloat(a *big.Float) *big.Float { return new(big.Float).SetPrec(a.Prec()) }
+
+// floatFromBool returns 1 or 0 as big.Float
+func floatFromBool(b bool) *big.Float { return big.NewFloat(FromBool(b)) }
+
+// ratFromBool returns 1 or 0 as big.Rat
+func ratFromBool(b bool) *big.Rat { return new(big.Rat).SetInt64(int64(FromBool(b))) }
+
+// checkFloat returns error if operation on big.Float gives NaN
+func checkFloat(name string, calc func() *big.Float) (res *big.Float, err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			if nan, ok := e.(big.ErrNaN); ok {
+				res, err = nil, fmt.Errorf("%s: %s", name, nan.Error())
+				return
+			}
+			panic(e)
+		}
+	}()
+	return calc(), nil
+}
@@ -0,0 +1,48 @@
+package op_test
+
+import (
+	"testing"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+func TestFloat64Precision(t *testing.T) {
+	var p op.Precision
+	// float32 would round it to 16777216
+	if v, err := p.ExecBinary(op.Add, "16777217", "0"); err != nil || v != "1.6777217e+07" {
+		t.Errorf("'16777217 + 0': value is not '1.6777217e+07', got '%s' (%v)", v, err)
+	}
+	if v, err := p.ExecFunction(op.Sin, "0"); err != nil || v != "0" {
+		t.Errorf("'sin(0)': value is not '0', got '%s' (%v)", v, err)
+	}
+}
+
+func TestRationalPrecision(t *testing.T) {
+	p := op.Precision{Kind: op.Rational}
+	if v, err := p.ExecBinary(op.Add, "1/3", "1/6"); err != nil || v != "1/2" {
+		t.Errorf("'1/3 + 1/6': value is not '1/2', got '%s' (%v)", v, err)
+	}
+	if v, err := p.ExecFunction(op.Sin, "1"); err == nil {
+		t.Errorf("'sin(1)': expected error, got '%s'", v)
+	}
+}
+
+func TestBigFloatPrecision(t *testing.T) {
+	short, long := op.Precision{Kind: op.BigFloat, Bits: 24}, op.Precision{Kind: op.BigFloat, Bits: 128}
+	if v, err := short.ExecBinary(op.Div, "1", "3"); err != nil || v != "0.33333334" {
+		t.Errorf("'1/3' with 24 bits: value is not '0.33333334', got '%s' (%v)", v, err)
+	}
+	if v, err := long.ExecBinary(op.Div, "1", "3"); err != nil || v != "0.333333333333333333333333333333333333334" {
+		t.Errorf("'1/3' with 128 bits: value is not '0.333333333333333333333333333333333333334', got '%s' (%v)", v, err)
+	}
+	if v, err := short.ExecBinary(op.Add, "16777217", "0"); err != nil || v != "1.6777216e+07" {
+		t.Errorf("'16777217 + 0' with 24 bits: value is not '1.6777216e+07', got '%s' (%v)", v, err)
+	}
+	// operands without big.Float implementation are calculated with big.Rat
+	if v, err := long.ExecBinary(op.Mod, "7.5", "2"); err != nil || v != "1.5" {
+		t.Errorf("'7.5 mod 2': value is not '1.5', got '%s' (%v)", v, err)
+	}
+	if v, err := long.ExecFunction(op.Sin, "1"); err == nil {
+		t.Errorf("'sin(1)' with 128 bits: expected error, got '%s'", v)
+	}
+}
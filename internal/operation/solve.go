@@ -0,0 +1,34 @@
+package op
+
+import "errors"
+
+// default tolerance and amount of iterations of solve, they are changed by its optional args:
+// "solve(x^2 = 2, x, 1, 1e-6, 20)"
+const (
+	DefaultSolveTolerance  = 1e-12
+	DefaultSolveIterations = 100
+)
+
+// EQUATION
+type equation struct{}
+
+func (e equation) math()          {}
+func (e equation) Symbol() string { return "=" }
+func (e equation) Name() string   { return "equation" }
+
+func (e equation) Exec(a, b float64) (float64, error) {
+	return 0, errors.New("equation can be written only as the first arg of solve")
+}
+
+// SOLVE finds root of equation by variable: "solve(x^2 = 2, x, 1)". it is calculated by storage,
+// which sends calculation of equation at every step to computation servers
+type solve struct{}
+
+func (s solve) math()             {}
+func (s solve) Symbol() string    { return "solve" }
+func (s solve) Name() string      { return "solve" }
+func (s solve) Arity() (int, int) { return 3, 5 }
+
+func (s solve) Exec(args ...float64) (float64, error) {
+	return 0, errors.New("solve is calculated by storage")
+}
@@ -0,0 +1,328 @@
+package op
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Dimension is a set of powers of SI base quantities:
+// length, mass, time, electric current, temperature, amount of substance and luminous intensity
+type Dimension [7]int
+
+// UnitInfo describes registered unit by its factor to SI base units and dimension: km is 1000 m
+type UnitInfo struct {
+	Factor    float64
+	Dimension Dimension
+}
+
+var (
+	dimLength      = Dimension{1, 0, 0, 0, 0, 0, 0}
+	dimMass        = Dimension{0, 1, 0, 0, 0, 0, 0}
+	dimDuration    = Dimension{0, 0, 1, 0, 0, 0, 0}
+	dimCurrent     = Dimension{0, 0, 0, 1, 0, 0, 0}
+	dimTemperature = Dimension{0, 0, 0, 0, 1, 0, 0}
+	dimAmount      = Dimension{0, 0, 0, 0, 0, 1, 0}
+	dimLuminosity  = Dimension{0, 0, 0, 0, 0, 0, 1}
+	dimVolume      = Dimension{3, 0, 0, 0, 0, 0, 0}
+	dimFrequency   = Dimension{0, 0, -1, 0, 0, 0, 0}
+	dimForce       = Dimension{1, 1, -2, 0, 0, 0, 0}
+	dimEnergy      = Dimension{2, 1, -2, 0, 0, 0, 0}
+	dimPower       = Dimension{2, 1, -3, 0, 0, 0, 0}
+	dimPressure    = Dimension{-1, 1, -2, 0, 0, 0, 0}
+	dimCharge      = Dimension{0, 0, 1, 1, 0, 0, 0}
+	dimVoltage     = Dimension{2, 1, -3, -1, 0, 0, 0}
+)
+
+// Units is the registry of units which can be written after numbers: "3 m + 20 cm".
+// minute isn't a unit, "min" is a function
+var Units = map[string]UnitInfo{
+	"m":   {Factor: 1, Dimension: dimLength},
+	"km":  {Factor: 1e3, Dimension: dimLength},
+	"cm":  {Factor: 1e-2, Dimension: dimLength},
+	"mm":  {Factor: 1e-3, Dimension: dimLength},
+	"kg":  {Factor: 1, Dimension: dimMass},
+	"g":   {Factor: 1e-3, Dimension: dimMass},
+	"mg":  {Factor: 1e-6, Dimension: dimMass},
+	"s":   {Factor: 1, Dimension: dimDuration},
+	"ms":  {Factor: 1e-3, Dimension: dimDuration},
+	"h":   {Factor: 3600, Dimension: dimDuration},
+	"A":   {Factor: 1, Dimension: dimCurrent},
+	"K":   {Factor: 1, Dimension: dimTemperature},
+	"mol": {Factor: 1, Dimension: dimAmount},
+	"cd":  {Factor: 1, Dimension: dimLuminosity},
+	"L":   {Factor: 1e-3, Dimension: dimVolume},
+	"Hz":  {Factor: 1, Dimension: dimFrequency},
+	"N":   {Factor: 1, Dimension: dimForce},
+	"J":   {Factor: 1, Dimension: dimEnergy},
+	"kJ":  {Factor: 1e3, Dimension: dimEnergy},
+	"W":   {Factor: 1, Dimension: dimPower},
+	"kW":  {Factor: 1e3, Dimension: dimPower},
+	"Pa":  {Factor: 1, Dimension: dimPressure},
+	"kPa": {Factor: 1e3, Dimension: dimPressure},
+	"C":   {Factor: 1, Dimension: dimCharge},
+	"V":   {Factor: 1, Dimension: dimVoltage},
+}
+
+// namedUnits are units which replace product of SI units with the same dimension: "kg*m/s^2" is "N"
+var namedUnits = []string{"N", "J", "W", "Pa", "C", "V", "m", "kg", "s", "A", "K", "mol", "cd"}
+
+// IsUnit returns true if name is registered unit
+func IsUnit(name string) bool {
+	_, ok := Units[name]
+	return ok
+}
+
+// Unit is a product of registered units in powers: {"kg": 1, "m": 1, "s": -2} is "kg*m/s^2"
+type Unit map[string]int
+
+// Quantity is a value with unit
+type Quantity struct {
+	Value float64
+	Unit  Unit
+}
+
+// unitMult is Mult with other priority, in reverse polish notation it is written as Mult
+type unitMult struct{ mult }
+
+// QuantityBinaryOperand can be calculated with quantities
+type QuantityBinaryOperand interface {
+	BinaryOperand
+	ExecQuantity(a, b Quantity) (Quantity, error)
+}
+
+// QuantityUnaryOperand can be calculated with quantities
+type QuantityUnaryOperand interface {
+	UnaryOperand
+	ExecQuantity(a Quantity) (Quantity, error)
+}
+
+// QuantityFunctionOperand can be calculated with quantities
+type QuantityFunctionOperand interface {
+	FunctionOperand
+	ExecQuantity(args ...Quantity) (Quantity, error)
+}
+
+// UnitNumber returns number of one unit: "1 m"
+func UnitNumber(name string) Number { return QuantityNumber(Quantity{Value: 1, Unit: Unit{name: 1}}) }
+
+// QuantityNumber returns number of quantity: "3.2 m", quantity without unit is a real number
+func QuantityNumber(q Quantity) Number {
+	return Number(formatQuantity(q, 'g'))
+}
+
+// IsQuantity returns true if number has unit
+func (n Number) IsQuantity() bool { return !n.IsInterval() && strings.Contains(string(n), " ") }
+
+// Quantity returns number as quantity, real number is a quantity without unit
+func (n Number) Quantity() (Quantity, error) {
+	valueText, unitText, _ := strings.Cut(string(n), " ")
+	v, err := strconv.ParseFloat(valueText, 64)
+	if err != nil && !errors.Is(err, strconv.ErrRange) {
+		r, ratErr := Number(valueText).Rat()
+		if ratErr != nil {
+			return Quantity{}, fmt.Errorf("'%s' is not a number", n)
+		}
+		v, _ = r.Float64()
+	}
+	u, err := ParseUnit(unitText)
+	return Quantity{Value: v, Unit: u}, err
+}
+
+// ParseUnit returns unit written as "kg*m/s^2", "1/s" or "m^2"
+func ParseUnit(text string) (Unit, error) {
+	u := Unit{}
+	if text == "" {
+		return u, nil
+	}
+	numerator, denominator, _ := strings.Cut(text, "/")
+	for sign, part := range map[int]string{1: numerator, -1: denominator} {
+		if part == "" || part == "1" {
+			continue
+		}
+		for _, factor := range strings.Split(part, "*") {
+			name, powText, hasPow := strings.Cut(factor, "^")
+			pow := 1
+			if hasPow {
+				p, err := strconv.Atoi(powText)
+				if err != nil {
+					return nil, fmt.Errorf("'%s' is not a unit", text)
+				}
+				pow = p
+			}
+			if !IsUnit(name) {
+				return nil, fmt.Errorf("unknown unit '%s'", name)
+			}
+			u[name] += sign * pow
+		}
+	}
+	return u.clean(), nil
+}
+
+func (u Unit) String() string {
+	var numerator, denominator []string
+	for _, name := range u.names() {
+		pow := u[name]
+		text := name
+		if pow != 1 && pow != -1 {
+			text += "^" + strconv.Itoa(absInt(pow))
+		}
+		if pow > 0 {
+			numerator = append(numerator, text)
+		} else {
+			denominator = append(denominator, text)
+		}
+	}
+	res := strings.Join(numerator, "*")
+	if len(denominator) == 0 {
+		return res
+	}
+	if res == "" {
+		res = "1"
+	}
+	return res + "/" + strings.Join(denominator, "*")
+}
+
+// names returns names of units in alphabetical order
+func (u Unit) names() []string {
+	names := make([]string, 0, len(u))
+	for name := range u {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// clean removes units with zero power
+func (u Unit) clean() Unit {
+	for name, pow := range u {
+		if pow == 0 {
+			delete(u, name)
+		}
+	}
+	return u
+}
+
+// Dimension returns dimension of unit
+func (u Unit) Dimension() Dimension {
+	var d Dimension
+	for name, pow := range u {
+		for i, p := range Units[name].Dimension {
+			d[i] += p * pow
+		}
+	}
+	return d
+}
+
+// Factor returns factor of unit to SI base units: "km/h" is 1/3.6
+func (u Unit) Factor() float64 {
+	f := 1.0
+	for name, pow := range u {
+		f *= math.Pow(Units[name].Factor, float64(pow))
+	}
+	return f
+}
+
+// Convert returns value of quantity in unit u, units must have the same dimension
+func (q Quantity) Convert(u Unit) (float64, error) {
+	if q.Unit.Dimension() != u.Dimension() {
+		return 0, fmt.Errorf("%s can't be converted to %s", q.unitName(), Quantity{Unit: u}.unitName())
+	}
+	return q.Value * q.Unit.Factor() / u.Factor(), nil
+}
+
+// unitName returns unit of quantity for errors: "m", "number"
+func (q Quantity) unitName() string {
+	if len(q.Unit) == 0 {
+		return "number"
+	}
+	return q.Unit.String()
+}
+
+// normalize joins units with the same dimension into the first of them: "m*cm" is "m^2",
+// replaces product of SI units with named unit: "kg*m/s^2" is "N", and returns real number
+// instead of quantity without dimension: "m/cm" is 100
+func (q Quantity) normalize(names []string) Quantity {
+	u := Unit{}
+	v := q.Value
+	for _, name := range names {
+		pow, ok := q.Unit[name]
+		if !ok || pow == 0 {
+			continue
+		}
+		target := name
+		for other := range u {
+			if Units[other].Dimension == Units[name].Dimension {
+				target = other
+				break
+			}
+		}
+		v *= math.Pow(Units[name].Factor/Units[target].Factor, float64(pow))
+		u[target] += pow
+	}
+	u.clean()
+	if u.Dimension() == (Dimension{}) {
+		return Quantity{Value: v * u.Factor()}
+	}
+	if len(u) > 1 && u.Factor() == 1 {
+		for _, name := range namedUnits {
+			if Units[name].Dimension == u.Dimension() {
+				return Quantity{Value: v, Unit: Unit{name: 1}}
+			}
+		}
+	}
+	return Quantity{Value: v, Unit: u}
+}
+
+// product returns quantity with unit a^powA * b^powB
+func product(value float64, a, b Unit, powB int) Quantity {
+	u := Unit{}
+	var names []string
+	for _, name := range a.names() {
+		u[name] += a[name]
+		names = append(names, name)
+	}
+	for _, name := range b.names() {
+		if _, ok := u[name]; !ok {
+			names = append(names, name)
+		}
+		u[name] += b[name] * powB
+	}
+	return Quantity{Value: value, Unit: u}.normalize(names)
+}
+
+// sameUnit returns values of quantities in unit of the first one, they must have the same dimension
+func sameUnit(name string, args ...Quantity) ([]float64, Unit, error) {
+	u := args[0].Unit
+	values := make([]float64, len(args))
+	for i, arg := range args {
+		if arg.Unit.Dimension() != u.Dimension() {
+			return nil, nil, fmt.Errorf("%s: %s and %s have different dimensions", name, args[0].unitName(), arg.unitName())
+		}
+		v, _ := arg.Convert(u)
+		values[i] = v
+	}
+	return values, u, nil
+}
+
+// withUnit returns quantity of value in unit u, which doesn't need normalizing
+func withUnit(v float64, u Unit) Quantity { return Quantity{Value: v, Unit: u} }
+
+// formatQuantity writes value of quantity in format of strconv.FormatFloat and its unit
+func formatQuantity(q Quantity, format byte) string {
+	res := strconv.FormatFloat(q.Value, format, -1, 64)
+	if len(q.Unit) == 0 {
+		return res
+	}
+	return res + " " + q.Unit.String()
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,39 @@
+package op_test
+
+import (
+	"testing"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+func TestUnits(t *testing.T) {
+	var p op.Precision
+	compareBinary(t, p, op.Add, "3 m", "20 cm", "3.2 m", false)
+	compareBinary(t, p, op.Add, "20 cm", "3 m", "320 cm", false)
+	compareBinary(t, p, op.Mult, "5 kg", "2 m/s^2", "10 N", false)
+	compareBinary(t, p, op.Div, "60 km", "2 h", "30 km/h", false)
+	compareBinary(t, p, op.Mult, "2 m", "50 cm", "1 m^2", false)
+	compareBinary(t, p, op.Mult, "3 N", "2 m", "6 J", false)
+	compareBinary(t, p, op.Div, "10 m", "2 cm", "500", false)
+	compareBinary(t, p, op.Greater, "1 km", "999 m", "1", false)
+	compareFunction(t, p, op.Sqrt, []op.Number{"16 m^2"}, "4 m", false)
+
+	compareBinary(t, p, op.Add, "1 m", "1 s", "", true)
+	compareBinary(t, p, op.Sub, "1 kg", "1 m", "", true)
+	compareBinary(t, p, op.Add, "1 m", "1", "", true)
+	compareBinary(t, p, op.Pow, "2 m", "0.5", "", true)
+	compareFunction(t, p, op.Sqrt, []op.Number{"2 m"}, "", true)
+}
+
+func TestTimeUnits(t *testing.T) {
+	var p op.Precision
+	compareBinary(t, p, op.Add, "1 h", "30m", "1h30m", false)
+	compareBinary(t, p, op.Add, "30m", "1 h", "1h30m", false)
+	compareBinary(t, p, op.Div, "2 h", "30m", "4", false)
+	compareBinary(t, p, op.Less, "30m", "1 h", "1", false)
+	compareBinary(t, p, op.Add, "2024-03-01", "2 h", "2024-03-01T02:00:00", false)
+	compareBinary(t, p, op.Div, "60 km", "2h", "30 km/h", false)
+
+	compareBinary(t, p, op.Add, "30 m", "30m", "", true)
+	compareBinary(t, p, op.Add, "2024-03-01", "2 m", "", true)
+}
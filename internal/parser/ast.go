@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+	"github.com/informitas/stack"
+)
+
+// Node is a node of expression tree, numbers are leaves
+type Node struct {
+	Token
+	Args []*Node
+}
+
+// Parse returns expression tree of infix expression
+func Parse(infixExpr string) (*Node, error) {
+	return ParseMode(infixExpr, Strict)
+}
+
+// ParseMode is Parse with additional syntax turned on by mode
+func ParseMode(infixExpr string, mode Mode) (*Node, error) {
+	tokens, err := ParseToRPNMode(infixExpr, mode)
+	if err != nil {
+		return nil, err
+	}
+	return FromRPN(tokens)
+}
+
+// FromRPN builds expression tree from tokens in reverse polish notation
+func FromRPN(tokens []Token) (*Node, error) {
+	nodes := stack.NewStack[*Node]()
+	for _, t := range tokens {
+		node := &Node{Token: t, Args: make([]*Node, t.ArgsCount())}
+		for i := len(node.Args) - 1; i >= 0; i-- {
+			arg, err := nodes.Pop()
+			if err != nil {
+				return nil, fmt.Errorf("not enough args for '%s'", t.Text)
+			}
+			node.Args[i] = arg
+		}
+		nodes.Push(node)
+	}
+	if nodes.Size() != 1 {
+		return nil, errorNotAllNumbersUsed
+	}
+	root, _ := nodes.Pop()
+	return root, nil
+}
+
+// RPN returns tokens of tree in reverse polish notation
+func (n *Node) RPN() []Token {
+	var tokens []Token
+	for _, arg := range n.Args {
+		tokens = append(tokens, arg.RPN()...)
+	}
+	return append(tokens, n.Token)
+}
+
+// Infix returns expression of tree in infix notation: "2*x*sin(x) + x^2*cos(x)". parens are written
+// only where priorities need them, operands with lower priority than multiplication and written
+// by words are separated by spaces
+func (n *Node) Infix() string {
+	switch n.Kind {
+	case NumberToken, NameToken:
+		return n.Text
+	case FunctionToken:
+		args := make([]string, len(n.Args))
+		for i, arg := range n.Args {
+			args[i] = arg.Infix()
+		}
+		return n.Text + "(" + strings.Join(args, ", ") + ")"
+	}
+	operand, err := n.Operand()
+	if err != nil {
+		return n.Text
+	}
+	info := op.OperationTable[operand]
+	symbol := writtenSymbol(operand)
+	switch operand.(type) {
+	case op.PrefixOperand:
+		return symbol + n.Args[0].infixArg(info.Priority, true)
+	case op.PostfixOperand:
+		return n.Args[0].infixArg(info.Priority, false) + symbol
+	}
+	if r, _ := utf8.DecodeRuneInString(symbol); info.Priority < op.OperationTable[op.Mult].Priority || unicode.IsLetter(r) {
+		symbol = " " + symbol + " "
+	}
+	left := n.Args[0].infixArg(info.Priority, info.Assoc == op.RightAssoc)
+	// prefix operand after binary one is written in parens: "x - (-y)"
+	right := n.Args[1].infixArg(info.Priority, info.Assoc == op.LeftAssoc || n.Args[1].isPrefix())
+	return left + symbol + right
+}
+
+// infixArg returns arg of operand with priority in parens if arg has lower priority,
+// arg with the same priority is written in parens if parensOnEqual is true
+func (n *Node) infixArg(priority int, parensOnEqual bool) string {
+	text := n.Infix()
+	if n.Kind != OperandToken {
+		return text
+	}
+	operand, err := n.Operand()
+	if err != nil {
+		return text
+	}
+	argPriority := op.OperationTable[operand].Priority
+	if argPriority < priority || (argPriority == priority && parensOnEqual) || (parensOnEqual && n.isPrefix()) {
+		return "(" + text + ")"
+	}
+	return text
+}
+
+// isPrefix returns true if node is prefix operand: "-x"
+func (n *Node) isPrefix() bool {
+	operand, err := n.Operand()
+	if err != nil {
+		return false
+	}
+	_, ok := operand.(op.PrefixOperand)
+	return ok
+}
+
+// writtenSymbol returns symbol operand is written by in infix expression: unary minus is "-"
+func writtenSymbol(operand op.Operand) string {
+	for binary, prefix := range op.PrefixForms {
+		if prefix == operand {
+			return binary.Symbol()
+		}
+	}
+	return operand.Symbol()
+}
+
+// BoundVariable returns variable which is bound in the first arg of node: "x" of "solve(x^2 = 2, x, 1)"
+// and "integrate(x^2, x, 0, 1)", "k" of summation "sum(k^2, k, 1, 100)". sum of four args is summation
+// if its second arg is a name used in the first one, otherwise it is sum of numbers
+func (n *Node) BoundVariable() (*Node, bool) {
+	if n.Kind != FunctionToken || len(n.Args) < 2 {
+		return nil, false
+	}
+	switch n.Text {
+	case op.Solve.Symbol(), op.Integrate.Symbol():
+		return n.Args[1], true
+	case op.Sum.Symbol():
+		variable := n.Args[1]
+		if len(n.Args) == 4 && variable.Kind == NameToken && dependsOn(n.Args[0], variable.Text) {
+			return variable, true
+		}
+	}
+	return nil, false
+}
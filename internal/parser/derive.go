@@ -0,0 +1,285 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+// Derive returns derivative of expression tree by variable: "x^2 * sin(x)" is "2*x*sin(x) + x^2*cos(x)".
+// names other than variable are constants. derivative is simplified while it is built:
+// numbers are calculated, and multiplication by 1 and adding of 0 are removed
+func Derive(node *Node, variable string) (*Node, error) {
+	switch node.Kind {
+	case NumberToken:
+		return numberNode(0), nil
+	case NameToken:
+		if node.Text == variable {
+			return numberNode(1), nil
+		}
+		return numberNode(0), nil
+	}
+	operand, err := node.Operand()
+	if err != nil {
+		return nil, err
+	}
+	if !dependsOn(node, variable) {
+		return numberNode(0), nil
+	}
+	args := make([]*Node, len(node.Args))
+	for i, arg := range node.Args {
+		// arg of condition isn't derived, it is calculated as it is
+		if operand == op.If && i == 0 {
+			continue
+		}
+		if args[i], err = Derive(arg, variable); err != nil {
+			return nil, err
+		}
+	}
+	a := node.Args[0]
+	switch operand {
+	case op.Add:
+		return addNodes(args[0], args[1]), nil
+	case op.Sub:
+		return subNodes(args[0], args[1]), nil
+	case op.Neg:
+		return negNode(args[0]), nil
+	case op.Pos:
+		return args[0], nil
+	case op.Percent:
+		return divNodes(args[0], numberNode(100)), nil
+	case op.Mult:
+		return addNodes(multNodes(args[0], node.Args[1]), multNodes(a, args[1])), nil
+	case op.Div:
+		b := node.Args[1]
+		return divNodes(subNodes(multNodes(args[0], b), multNodes(a, args[1])), powNodes(b, numberNode(2))), nil
+	case op.Pow:
+		return derivePow(node, args), nil
+	case op.Sin:
+		return multNodes(callNode(op.Cos, a), args[0]), nil
+	case op.Cos:
+		return negNode(multNodes(callNode(op.Sin, a), args[0])), nil
+	case op.Sqrt:
+		return divNodes(args[0], multNodes(numberNode(2), callNode(op.Sqrt, a))), nil
+	case op.Abs:
+		return multNodes(args[0], divNodes(a, callNode(op.Abs, a))), nil
+	case op.Log:
+		if len(node.Args) == 1 {
+			return divNodes(args[0], a), nil
+		}
+		if !dependsOn(node.Args[1], variable) {
+			return divNodes(args[0], multNodes(a, callNode(op.Log, node.Args[1]))), nil
+		}
+		// logarithm by base is ratio of natural logarithms
+		return Derive(divNodes(callNode(op.Log, a), callNode(op.Log, node.Args[1])), variable)
+	case op.If:
+		return callNode(op.If, node.Args[0], args[1], args[2]), nil
+	}
+	return nil, fmt.Errorf("derivative of '%s' is not supported", operand.Symbol())
+}
+
+// derivePow returns derivative of a^b by derivatives of args: n*a^(n-1)*a' if b is a constant,
+// a^b*log(a)*b' if a is a constant and a^b*(b'*log(a) + b*a'/a) in other cases. log(e) isn't written
+func derivePow(node *Node, args []*Node) *Node {
+	a, b := node.Args[0], node.Args[1]
+	logA := callNode(op.Log, a)
+	if a.Kind == NameToken && a.Text == "e" {
+		logA = numberNode(1)
+	}
+	switch {
+	case isNumber(args[1], 0):
+		return multNodes(multNodes(b, powNodes(a, subNodes(b, numberNode(1)))), args[0])
+	case isNumber(args[0], 0):
+		return multNodes(multNodes(node, logA), args[1])
+	}
+	return multNodes(node, addNodes(multNodes(args[1], logA), divNodes(multNodes(b, args[0]), a)))
+}
+
+// dependsOn returns true if variable is used in tree
+func dependsOn(node *Node, variable string) bool {
+	if node.Kind == NameToken {
+		return node.Text == variable
+	}
+	for _, arg := range node.Args {
+		if dependsOn(arg, variable) {
+			return true
+		}
+	}
+	return false
+}
+
+// numberNode returns tree of number, negative number is unary minus of number
+func numberNode(v float64) *Node {
+	tree, _ := FromRPN(NumberTokens(v))
+	return tree
+}
+
+// numberValue returns value of number tree: "2", "-2"
+func numberValue(node *Node) (float64, bool) {
+	if node.Kind == OperandToken && node.Text == op.Neg.Symbol() {
+		v, ok := numberValue(node.Args[0])
+		return -v, ok
+	}
+	if node.Kind != NumberToken {
+		return 0, false
+	}
+	v, err := ParseNumber(node.Text)
+	return v, err == nil
+}
+
+// isNumber returns true if tree is number v
+func isNumber(node *Node, v float64) bool {
+	value, ok := numberValue(node)
+	return ok && value == v
+}
+
+func operandNode(operand op.Operand, args ...*Node) *Node {
+	return &Node{Token: Token{Kind: OperandToken, Text: operand.Symbol()}, Args: args}
+}
+
+func callNode(fn op.FunctionOperand, args ...*Node) *Node {
+	return &Node{Token: Token{Kind: FunctionToken, Text: fn.Symbol(), Args: len(args)}, Args: args}
+}
+
+// foldNumbers returns number calculated by calc if both args are numbers and result is finite
+func foldNumbers(a, b *Node, calc func(a, b float64) float64) (*Node, bool) {
+	x, okA := numberValue(a)
+	y, okB := numberValue(b)
+	if !okA || !okB {
+		return nil, false
+	}
+	res := calc(x, y)
+	if math.IsInf(res, 0) || math.IsNaN(res) {
+		return nil, false
+	}
+	return numberNode(res), true
+}
+
+func addNodes(a, b *Node) *Node {
+	if res, ok := foldNumbers(a, b, func(x, y float64) float64 { return x + y }); ok {
+		return res
+	}
+	switch {
+	case isNumber(a, 0):
+		return b
+	case isNumber(b, 0):
+		return a
+	case b.isNeg():
+		return subNodes(a, b.Args[0])
+	}
+	return operandNode(op.Add, a, b)
+}
+
+func subNodes(a, b *Node) *Node {
+	if res, ok := foldNumbers(a, b, func(x, y float64) float64 { return x - y }); ok {
+		return res
+	}
+	switch {
+	case isNumber(b, 0):
+		return a
+	case isNumber(a, 0):
+		return negNode(b)
+	case a.equal(b):
+		return numberNode(0)
+	case b.isNeg():
+		return addNodes(a, b.Args[0])
+	}
+	return operandNode(op.Sub, a, b)
+}
+
+// negNode returns -a, minus of product or quotient is written as minus of its number: "-2*x"
+func negNode(a *Node) *Node {
+	if v, ok := numberValue(a); ok {
+		return numberNode(-v)
+	}
+	if a.isNeg() {
+		return a.Args[0]
+	}
+	if a.Kind == OperandToken && (a.Text == op.Mult.Symbol() || a.Text == op.Div.Symbol()) {
+		if v, ok := numberValue(a.Args[0]); ok {
+			return &Node{Token: a.Token, Args: []*Node{numberNode(-v), a.Args[1]}}
+		}
+	}
+	return operandNode(op.Neg, a)
+}
+
+func multNodes(a, b *Node) *Node {
+	if res, ok := foldNumbers(a, b, func(x, y float64) float64 { return x * y }); ok {
+		return res
+	}
+	switch {
+	case isNumber(a, 0) || isNumber(b, 0):
+		return numberNode(0)
+	case isNumber(a, 1):
+		return b
+	case isNumber(b, 1):
+		return a
+	case isNumber(a, -1):
+		return negNode(b)
+	case isNumber(b, -1):
+		return negNode(a)
+	case b.isNeg():
+		return negNode(multNodes(a, b.Args[0]))
+	case a.isNeg():
+		if _, ok := numberValue(a); !ok {
+			return negNode(multNodes(a.Args[0], b))
+		}
+	}
+	// number is written before other factors, numbers of factors are multiplied: "3 * (2*x)" is "6*x"
+	if _, ok := numberValue(b); ok {
+		a, b = b, a
+	}
+	if x, ok := numberValue(a); ok && b.Kind == OperandToken && b.Text == op.Mult.Symbol() {
+		if y, ok := numberValue(b.Args[0]); ok {
+			return multNodes(numberNode(x*y), b.Args[1])
+		}
+	}
+	return operandNode(op.Mult, a, b)
+}
+
+func divNodes(a, b *Node) *Node {
+	// only exact quotients are calculated, so "1/3" isn't written as decimal
+	if x, ok := numberValue(a); ok && !isNumber(b, 0) {
+		if y, ok := numberValue(b); ok && math.Mod(x, y) == 0 {
+			return numberNode(x / y)
+		}
+	}
+	switch {
+	case isNumber(a, 0) && !isNumber(b, 0):
+		return numberNode(0)
+	case isNumber(b, 1):
+		return a
+	case a.equal(b):
+		return numberNode(1)
+	}
+	return operandNode(op.Div, a, b)
+}
+
+func powNodes(a, b *Node) *Node {
+	switch {
+	case isNumber(b, 0):
+		return numberNode(1)
+	case isNumber(b, 1):
+		return a
+	}
+	return operandNode(op.Pow, a, b)
+}
+
+// equal returns true if trees are the same
+func (n *Node) equal(other *Node) bool {
+	if n.Kind != other.Kind || n.Text != other.Text || len(n.Args) != len(other.Args) {
+		return false
+	}
+	for i, arg := range n.Args {
+		if !arg.equal(other.Args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// isNeg returns true if node is unary minus: "-x"
+func (n *Node) isNeg() bool {
+	return n.Kind == OperandToken && n.Text == op.Neg.Symbol()
+}
@@ -0,0 +1,124 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	errorNotAllNumbersUsed error = fmt.Errorf("not all numbers are involved in mathematical operations")
+	errorNotClosedParen          = fmt.Errorf("paren doesn't closed")
+	errorNoOpenParen             = fmt.Errorf("closed paren located before open paren")
+	errorMissingNumber           = fmt.Errorf("number is missing")
+	errorUnexpectedNumber        = fmt.Errorf("number is located right after another number")
+	errorCommaOutsideCall        = fmt.Errorf("comma is located outside of function call")
+	errorUnknownOperand          = fmt.Errorf("unknown operand")
+	errorUnknownFunction         = fmt.Errorf("unknown function")
+	errorCallWithoutParens       = fmt.Errorf("function is called without parens")
+	errorPrefixAfterNumber       = fmt.Errorf("prefix operand is located after number")
+	errorWrongArgsCount          = fmt.Errorf("wrong amount of function args")
+	errorInvalidNumber           = fmt.Errorf("invalid number")
+	errorUnknownName             = fmt.Errorf("unknown name")
+	errorNoElse                  = fmt.Errorf("condition '?' has no ':' part")
+	errorNoThen                  = fmt.Errorf("':' is located outside of condition")
+	errorMisplacedEquation       = fmt.Errorf("equation can be written only as the first arg of solve")
+)
+
+// ErrorCode identifies kind of syntax error
+type ErrorCode string
+
+const (
+	CodeNotAllNumbersUsed ErrorCode = "not_all_numbers_used"
+	CodeNotClosedParen    ErrorCode = "not_closed_paren"
+	CodeNoOpenParen       ErrorCode = "no_open_paren"
+	CodeMissingNumber     ErrorCode = "missing_number"
+	CodeUnexpectedNumber  ErrorCode = "unexpected_number"
+	CodeCommaOutsideCall  ErrorCode = "comma_outside_call"
+	CodeUnknownOperand    ErrorCode = "unknown_operand"
+	CodeUnknownFunction   ErrorCode = "unknown_function"
+	CodeCallWithoutParens ErrorCode = "call_without_parens"
+	CodePrefixAfterNumber ErrorCode = "prefix_after_number"
+	CodeWrongArgsCount    ErrorCode = "wrong_args_count"
+	CodeInvalidNumber     ErrorCode = "invalid_number"
+	CodeUnknownName       ErrorCode = "unknown_name"
+	CodeNoElse            ErrorCode = "no_else"
+	CodeNoThen            ErrorCode = "no_then"
+	CodeMisplacedEquation ErrorCode = "misplaced_equation"
+	CodeInvalid           ErrorCode = "invalid"
+)
+
+var errorCodes = map[error]ErrorCode{
+	errorNotAllNumbersUsed: CodeNotAllNumbersUsed,
+	errorNotClosedParen:    CodeNotClosedParen,
+	errorNoOpenParen:       CodeNoOpenParen,
+	errorMissingNumber:     CodeMissingNumber,
+	errorUnexpectedNumber:  CodeUnexpectedNumber,
+	errorCommaOutsideCall:  CodeCommaOutsideCall,
+	errorUnknownOperand:    CodeUnknownOperand,
+	errorUnknownFunction:   CodeUnknownFunction,
+	errorCallWithoutParens: CodeCallWithoutParens,
+	errorPrefixAfterNumber: CodePrefixAfterNumber,
+	errorWrongArgsCount:    CodeWrongArgsCount,
+	errorInvalidNumber:     CodeInvalidNumber,
+	errorUnknownName:       CodeUnknownName,
+	errorNoElse:            CodeNoElse,
+	errorNoThen:            CodeNoThen,
+	errorMisplacedEquation: CodeMisplacedEquation,
+}
+
+// SyntaxError describes where and why infix expression can't be parsed
+type SyntaxError struct {
+	// Offset is a byte offset of Token in expression
+	Offset int `json:"offset"`
+	// Column is a number of the first rune of Token in expression, starting from 1
+	Column  int       `json:"column"`
+	Token   string    `json:"token"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+	// Snippet is the expression with a caret line under Token:
+	//  2 + * 3
+	//      ^
+	Snippet string `json:"snippet"`
+
+	err error
+}
+
+func newSyntaxError(expr string, offset int, token string, err error) *SyntaxError {
+	code := CodeInvalid
+	for e, c := range errorCodes {
+		if errors.Is(err, e) {
+			code = c
+			break
+		}
+	}
+	column := utf8.RuneCountInString(expr[:offset]) + 1
+	width := utf8.RuneCountInString(token)
+	if width == 0 {
+		width = 1
+	}
+	snippet := fmt.Sprintf("%s\n%s%s", expr, strings.Repeat(" ", column-1), strings.Repeat("^", width))
+	return &SyntaxError{
+		Offset:  offset,
+		Column:  column,
+		Token:   token,
+		Code:    code,
+		Message: err.Error(),
+		Snippet: snippet,
+		err:     err,
+	}
+}
+
+// UnknownNameError returns error for name token of expr which value can't be found
+func UnknownNameError(expr string, t Token) *SyntaxError {
+	return newSyntaxError(expr, t.Offset, t.Text, fmt.Errorf("%w '%s'", errorUnknownName, t.Text))
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at column %d", e.Message, e.Column)
+}
+
+func (e *SyntaxError) Unwrap() error {
+	return e.err
+}
@@ -0,0 +1,401 @@
+package parser
+
+import (
+	"fmt"
+	"math/big"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+func isDecimalDigit(b byte) bool { return '0' <= b && b <= '9' }
+func isBinaryDigit(b byte) bool  { return b == '0' || b == '1' }
+func isHexDigit(b byte) bool {
+	return isDecimalDigit(b) || ('a' <= b && b <= 'f') || ('A' <= b && b <= 'F')
+}
+
+// numberBases maps prefixes of integer literals to their base
+var numberBases = map[string]int{
+	"0x": 16, "0X": 16,
+	"0b": 2, "0B": 2,
+}
+
+var baseDigits = map[int]func(byte) bool{
+	16: isHexDigit,
+	2:  isBinaryDigit,
+}
+
+// LexNumber returns number literal expr starts with:
+// decimal "12.5", ".5", "6.02E23", "1e-9", imaginary "2i", hexadecimal "0x1F", binary "0b1010".
+// digits can be separated by underscores: "1_000_000".
+// if literal is malformed, returned string is its malformed part
+func LexNumber(expr string) (string, error) {
+	if len(expr) >= 2 {
+		if base, ok := numberBases[expr[:2]]; ok {
+			return lexIntegerNumber(expr, base)
+		}
+	}
+
+	end, err := scanDigits(expr, 0, isDecimalDigit)
+	if err != nil {
+		return malformedNumber(expr), err
+	}
+	hasDigits := end > 0
+	if end < len(expr) && expr[end] == '.' {
+		fractionEnd, err := scanDigits(expr, end+1, isDecimalDigit)
+		if err != nil {
+			return malformedNumber(expr), err
+		}
+		hasDigits = hasDigits || fractionEnd > end+1
+		end = fractionEnd
+	}
+	if !hasDigits {
+		return malformedNumber(expr), fmt.Errorf("number has no digits")
+	}
+	// "e" is an exponent only if digits follow it, otherwise it is a name after number: "2e"
+	if end < len(expr) && (expr[end] == 'e' || expr[end] == 'E') {
+		start := end + 1
+		if start < len(expr) && (expr[start] == '+' || expr[start] == '-') {
+			start++
+		}
+		if start < len(expr) && isDecimalDigit(expr[start]) {
+			if end, err = scanDigits(expr, start, isDecimalDigit); err != nil {
+				return malformedNumber(expr), err
+			}
+		}
+	}
+	if end < len(expr) && expr[end] == '.' {
+		return malformedNumber(expr), fmt.Errorf("number has misplaced dot")
+	}
+	// "i" is an imaginary suffix only if it isn't a start of name: "2i", but "2in"
+	if end < len(expr) && expr[end] == op.ImaginaryUnit[0] && GetStringName(expr[end:]) == op.ImaginaryUnit {
+		end++
+	}
+	return expr[:end], nil
+}
+
+// LexInterval returns interval literal expr starts with and its form without spaces:
+// "[1.9, 2.1]" and "[1.9,2.1]". bounds are number literals with optional sign: "[-1, 1e3]"
+func LexInterval(expr string) (string, string, error) {
+	if !strings.HasPrefix(expr, "[") {
+		return "", "", fmt.Errorf("interval must start with '['")
+	}
+	var (
+		i      = 1
+		bounds []string
+	)
+	skipSpaces := func() {
+		for i < len(expr) && expr[i] == ' ' {
+			i++
+		}
+	}
+	for _, end := range []byte{',', ']'} {
+		skipSpaces()
+		start := i
+		if i < len(expr) && (expr[i] == '-' || expr[i] == '+') {
+			i++
+		}
+		num, err := LexNumber(expr[i:])
+		if err != nil {
+			return expr[:i+len(num)], "", err
+		}
+		i += len(num)
+		bounds = append(bounds, expr[start:i])
+		skipSpaces()
+		if i >= len(expr) || expr[i] != end {
+			return strings.TrimRight(expr[:i], " "), "", fmt.Errorf("interval bound must be followed by '%c'", end)
+		}
+		i++
+	}
+	return expr[:i], "[" + strings.Join(bounds, ",") + "]", nil
+}
+
+// LexMatrix returns matrix literal expr starts with and its form without spaces:
+// "[[1, 2], [3, 4]]" and "[[1,2],[3,4]]". elements are number literals with optional sign,
+// rows must have equal length
+func LexMatrix(expr string) (string, string, error) {
+	if !strings.HasPrefix(expr, "[") {
+		return "", "", fmt.Errorf("matrix must start with '['")
+	}
+	var (
+		i    = skipSpaces(expr, 1)
+		rows []string
+	)
+	for {
+		end, elements, err := lexElements(expr, i, "matrix row")
+		if err != nil {
+			return expr[:end], "", err
+		}
+		if len(rows) > 0 && len(elements) != strings.Count(rows[0], ",")+1 {
+			return expr[:end], "", fmt.Errorf("rows of matrix have different length")
+		}
+		rows = append(rows, "["+strings.Join(elements, ",")+"]")
+		i = skipSpaces(expr, end)
+		if i < len(expr) && expr[i] == ',' {
+			i = skipSpaces(expr, i+1)
+			continue
+		}
+		if i >= len(expr) || expr[i] != ']' {
+			return expr[:end], "", fmt.Errorf("matrix row must be followed by ',' or ']'")
+		}
+		return expr[:i+1], "[" + strings.Join(rows, ",") + "]", nil
+	}
+}
+
+// LexList returns list literal expr starts with and its elements: "[1, -2, 3]" has elements "1", "-2", "3".
+// elements are number literals with optional sign
+func LexList(expr string) (string, []string, error) {
+	end, elements, err := lexElements(expr, 0, "list")
+	return expr[:end], elements, err
+}
+
+// lexElements returns end of elements in square brackets which start at start and elements without spaces.
+// if elements are malformed, returned end is the end of their correct part
+func lexElements(expr string, start int, what string) (int, []string, error) {
+	if start >= len(expr) || expr[start] != '[' {
+		return start, nil, fmt.Errorf("%s must start with '['", what)
+	}
+	var (
+		i        = start + 1
+		elements []string
+	)
+	for {
+		i = skipSpaces(expr, i)
+		elementStart := i
+		if i < len(expr) && (expr[i] == '-' || expr[i] == '+') {
+			i++
+		}
+		num, err := LexNumber(expr[i:])
+		if err != nil {
+			return i + len(num), nil, err
+		}
+		i += len(num)
+		elements = append(elements, expr[elementStart:i])
+		end := i
+		i = skipSpaces(expr, i)
+		switch {
+		case i < len(expr) && expr[i] == ',':
+			i++
+		case i < len(expr) && expr[i] == ']':
+			return i + 1, elements, nil
+		default:
+			return end, nil, fmt.Errorf("element of %s must be followed by ',' or ']'", what)
+		}
+	}
+}
+
+// skipSpaces returns index of the first byte after spaces starting from i
+func skipSpaces(expr string, i int) int {
+	for i < len(expr) && expr[i] == ' ' {
+		i++
+	}
+	return i
+}
+
+// isMatrixLiteral returns true if expr starts with "[[", spaces between brackets are allowed
+func isMatrixLiteral(expr string) bool {
+	return strings.HasPrefix(expr, "[") && strings.HasPrefix(strings.TrimLeft(expr[1:], " "), "[")
+}
+
+var (
+	dateLiteral     = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}(:\d{2}(\.\d+)?)?)?`)
+	durationLiteral = regexp.MustCompile(`^(\d+(\.\d+)?(ms|w|d|h|m|s))+`)
+)
+
+// LexDate returns date literal expr starts with or empty string: "2024-03-01", "2024-03-01T10:30".
+// month and day have two digits, so "2024-3-1" is subtraction
+func LexDate(expr string) string {
+	return endOfLiteral(expr, dateLiteral.FindString(expr))
+}
+
+// LexDuration returns duration literal expr starts with or empty string: "30d", "1h30m", "1.5s", "500ms".
+// units are written without spaces, "2 h" is a number with unit
+func LexDuration(expr string) string {
+	return endOfLiteral(expr, durationLiteral.FindString(expr))
+}
+
+// endOfLiteral returns literal if it isn't followed by letter, digit or dot: "2min" isn't a duration
+func endOfLiteral(expr, literal string) string {
+	if literal == "" || len(literal) == len(expr) {
+		return literal
+	}
+	if r, _ := utf8.DecodeRuneInString(expr[len(literal):]); unicode.IsLetter(r) || unicode.IsDigit(r) || r == '.' || r == '_' {
+		return ""
+	}
+	return literal
+}
+
+func lexIntegerNumber(expr string, base int) (string, error) {
+	end, err := scanDigits(expr, 2, baseDigits[base])
+	if err != nil {
+		return malformedNumber(expr), err
+	}
+	if end == 2 {
+		return malformedNumber(expr), fmt.Errorf("number with base %d has no digits", base)
+	}
+	if end < len(expr) {
+		if r, _ := utf8.DecodeRuneInString(expr[end:]); r == '.' || unicode.IsDigit(r) || unicode.IsLetter(r) {
+			return malformedNumber(expr), fmt.Errorf("invalid digit '%c' in number with base %d", r, base)
+		}
+	}
+	return expr[:end], nil
+}
+
+// scanDigits returns end of digits and separating underscores starting from start
+func scanDigits(expr string, start int, isDigit func(byte) bool) (int, error) {
+	i := start
+	for i < len(expr) {
+		if isDigit(expr[i]) {
+			i++
+			continue
+		}
+		if expr[i] != '_' {
+			break
+		}
+		if i == start || !isDigit(expr[i-1]) || i+1 >= len(expr) || !isDigit(expr[i+1]) {
+			return i, fmt.Errorf("underscore must separate digits")
+		}
+		i++
+	}
+	return i, nil
+}
+
+// malformedNumber returns the part of expr which looks like number to show it in error
+func malformedNumber(expr string) string {
+	for i, r := range expr {
+		if !(unicode.IsDigit(r) || unicode.IsLetter(r) || r == '.' || r == '_') {
+			return expr[:i]
+		}
+	}
+	return expr
+}
+
+// ParseNumber returns value of number literal returned by LexNumber
+func ParseNumber(literal string) (float64, error) {
+	digits, err := DecimalNumber(literal)
+	if err != nil {
+		return 0, err
+	}
+	if op.Number(digits).IsComplex() {
+		return 0, fmt.Errorf("number '%s' is imaginary", literal)
+	}
+	if op.Number(digits).IsMatrix() {
+		return 0, fmt.Errorf("number '%s' is a matrix", literal)
+	}
+	if op.Number(digits).IsInterval() {
+		return 0, fmt.Errorf("number '%s' is an interval", literal)
+	}
+	if op.Number(digits).IsDate() || op.Number(digits).IsDuration() {
+		return 0, fmt.Errorf("number '%s' is a date or a duration", literal)
+	}
+	v, err := strconv.ParseFloat(digits, 64)
+	if err != nil {
+		if numErr, ok := err.(*strconv.NumError); ok && numErr.Err == strconv.ErrRange {
+			return 0, fmt.Errorf("number '%s' is out of range", literal)
+		}
+		return 0, err
+	}
+	return v, nil
+}
+
+// DecimalNumber returns number literal returned by LexNumber as decimal string without rounding:
+// underscores are removed, hexadecimal and binary integers are converted to decimal.
+// bounds of interval literal returned by LexInterval are converted the same way: "[0x1, 1_000]" is "[1,1000]".
+// matrix literal returned by LexMatrix is converted by elements: "[[0x1, 2]]" is "[[1,2]]".
+// dates and durations are written in the form of op.TimeNumber: "90m" is "1h30m"
+func DecimalNumber(literal string) (string, error) {
+	if isMatrixLiteral(literal) {
+		return decimalMatrix(literal)
+	}
+	if strings.HasPrefix(literal, "[") {
+		return decimalInterval(literal)
+	}
+	if LexDate(literal) == literal || LexDuration(literal) == literal {
+		v, err := op.Number(literal).Time()
+		if err != nil || v.Kind == op.RealKind {
+			return "", fmt.Errorf("'%s' is not a date or a duration", literal)
+		}
+		return string(op.TimeNumber(v)), nil
+	}
+	lexed, err := LexNumber(literal)
+	if err != nil {
+		return "", err
+	}
+	if lexed != literal {
+		return "", fmt.Errorf("'%s' is not a number", literal)
+	}
+	digits := strings.ReplaceAll(literal, "_", "")
+	if len(digits) > 2 {
+		if base, ok := numberBases[digits[:2]]; ok {
+			v, _ := new(big.Int).SetString(digits[2:], base)
+			return v.String(), nil
+		}
+	}
+	return digits, nil
+}
+
+func decimalMatrix(literal string) (string, error) {
+	lexed, normalized, err := LexMatrix(literal)
+	if err != nil {
+		return "", err
+	}
+	if lexed != literal {
+		return "", fmt.Errorf("'%s' is not a matrix", literal)
+	}
+	m, err := op.Number(normalized).Matrix()
+	if err != nil {
+		return "", err
+	}
+	for _, row := range m {
+		for j, el := range row {
+			sign, text := "", string(el)
+			if text[0] == '-' || text[0] == '+' {
+				sign, text = text[:1], text[1:]
+			}
+			digits, err := DecimalNumber(text)
+			if err != nil {
+				return "", err
+			}
+			if op.Number(digits).IsComplex() {
+				return "", fmt.Errorf("matrix element '%s' is imaginary", text)
+			}
+			row[j] = op.Number(strings.TrimPrefix(sign, "+") + digits)
+		}
+	}
+	return string(op.MatrixNumber(m)), nil
+}
+
+func decimalInterval(literal string) (string, error) {
+	lexed, normalized, err := LexInterval(literal)
+	if err != nil {
+		return "", err
+	}
+	if lexed != literal {
+		return "", fmt.Errorf("'%s' is not an interval", literal)
+	}
+	bounds := strings.Split(strings.Trim(normalized, "[]"), ",")
+	for i, bound := range bounds {
+		sign := ""
+		if bound[0] == '-' || bound[0] == '+' {
+			sign, bound = bound[:1], bound[1:]
+		}
+		digits, err := DecimalNumber(bound)
+		if err != nil {
+			return "", err
+		}
+		if op.Number(digits).IsComplex() {
+			return "", fmt.Errorf("interval bound '%s' is imaginary", bound)
+		}
+		bounds[i] = strings.TrimPrefix(sign, "+") + digits
+	}
+	res := "[" + strings.Join(bounds, ",") + "]"
+	// checks order of bounds
+	if _, err := op.Number(res).Interval(); err != nil {
+		return "", err
+	}
+	return res, nil
+}
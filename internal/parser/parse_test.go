@@ -1,8 +1,12 @@
 package parser_test
 
 import (
+	"encoding/json"
+	"errors"
+	"math"
 	"testing"
 
+	op "github.com/XJIeI5/calculator/internal/operation"
 	"github.com/XJIeI5/calculator/internal/parser"
 )
 
@@ -13,6 +17,7 @@ func TestIgoneSpace(t *testing.T) {
 func TestProcedureOfActions(t *testing.T) {
 	compare(t, "2 + 2 * 2", "2 2 2 * + ", false)
 	compare(t, "200 / 5 + 1", "200 5 / 1 + ", false)
+	compare(t, "1 + 2 * 3 - 4", "1 2 3 * + 4 - ", false)
 }
 
 func TestChangeOrderByParens(t *testing.T) {
@@ -40,6 +45,520 @@ func TestFloatNumber(t *testing.T) {
 	compare(t, "2.5 + 5", "2.5 5 + ", true)
 }
 
+func TestNumberLiterals(t *testing.T) {
+	compare(t, "1e-9 + 6.02E23", "1e-9 6.02E23 + ", false)
+	compare(t, "0x1F * 0b1010", "0x1F 0b1010 * ", false)
+	compare(t, "1_000_000 - .5", "1_000_000 .5 - ", false)
+	compare(t, "2e+3^2", "2e+3 2 ^ ", false)
+	compare(t, "1.2.3", "", true)
+	compare(t, "1e+ 2", "", true)
+	compare(t, "0x", "", true)
+	compare(t, "0b102", "", true)
+	compare(t, "1__000", "", true)
+	compare(t, "1_", "", true)
+	compare(t, ". + 1", "", true)
+
+	checkNumber(t, "1e-9", 1e-9)
+	checkNumber(t, "6.02E23", 6.02e23)
+	checkNumber(t, "0x1F", 31)
+	checkNumber(t, "0b1010", 10)
+	checkNumber(t, "1_000_000", 1000000)
+	checkNumber(t, ".5", 0.5)
+	checkNumber(t, "5.", 5)
+	checkSyntaxError(t, "2 * 1.2.3", parser.CodeInvalidNumber, 4, 5, "1.2.3", "2 * 1.2.3\n    ^^^^^")
+}
+
+func TestIntervals(t *testing.T) {
+	compare(t, "[1.9, 2.1] * 3", "[1.9,2.1] 3 * ", false)
+	compare(t, "-[-1, +0x10]", "[-1,+0x10] u- ", false)
+	compare(t, "sqrt([4,9])", "[4,9] sqrt:1 ", false)
+	compare(t, "[2, 1]", "", true)
+	compare(t, "[1 2]", "", true)
+	compare(t, "[1, 2", "", true)
+	compare(t, "[1, 2i]", "", true)
+	compare(t, "2 [1, 2]", "", true)
+	compareMode(t, "2[1, 2]", "2 [1,2] * ", parser.Lenient)
+
+	tokens, err := parser.ParseToRPN("[-1, +0x10]")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	if v, err := tokens[0].Decimal(); err != nil || v != "[-1,16]" {
+		t.Errorf("value is not '[-1,16]', got '%s' (%v)", v, err)
+	}
+	checkSyntaxError(t, "1 + [1, 2 3]", parser.CodeInvalidNumber, 4, 5, "[1, 2", "1 + [1, 2 3]\n    ^^^^^")
+}
+
+func TestMatrices(t *testing.T) {
+	compare(t, "[[1, 2], [3, 4]] * [[1], [1]]", "[[1,2],[3,4]] [[1],[1]] * ", false)
+	compare(t, "det([ [1,2] , [3,4] ])", "[[1,2],[3,4]] det:1 ", false)
+	compare(t, "transpose([[1, -2, +3]]) + 1", "[[1,-2,+3]] transpose:1 1 + ", false)
+	compare(t, "[[1, 2], [3]]", "", true)
+	compare(t, "[[1, 2]", "", true)
+	compare(t, "[[1, 2i]]", "", true)
+
+	tokens, err := parser.ParseToRPN("[[0x10, -1_000]]")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	if v, err := tokens[0].Decimal(); err != nil || v != "[[16,-1000]]" {
+		t.Errorf("value is not '[[16,-1000]]', got '%s' (%v)", v, err)
+	}
+	if _, err := parser.ParseNumber("[[1]]"); err == nil {
+		t.Errorf("expected error for matrix")
+	}
+}
+
+func TestLists(t *testing.T) {
+	compare(t, "sum([1, 2, 3])", "1 2 3 sum:3 ", false)
+	compare(t, "median([1, 2]) + 1", "1 2 median:2 1 + ", false)
+	compare(t, "max([-1, +2])", "1 u- 2 max:2 ", false)
+	compare(t, "max([-1, +2], 3)", "[-1,+2] 3 max:2 ", false)
+	compare(t, "min([1,2],[3,4])", "[1,2] [3,4] min:2 ", false)
+	compare(t, "max(0, [1, 2])", "0 [1,2] max:2 ", false)
+	compare(t, "stddev([0x10])", "0x10 stddev:1 ", false)
+	compare(t, "avg([2, 1] * 2)", "", true)
+	compare(t, "sqrt([1, 2, 3])", "", true)
+	compare(t, "sum([1, 2,])", "", true)
+	compare(t, "[1, 2, 3]", "", true)
+}
+
+func TestSolve(t *testing.T) {
+	compareMode(t, "solve(x^3 - 2x - 5 = 0, x, 2)", "x 3 ^ 2 x * - 5 - 0 = x 2 solve:3 ", parser.Lenient)
+	compare(t, "solve(x^2 = 2, x, [1, 2], 1e-6, 20)", "x 2 ^ 2 = x [1,2] 1e-6 20 solve:5 ", false)
+	compare(t, "solve(x == 1 = x, x, 0)", "x 1 == x = x 0 solve:3 ", false)
+	compare(t, "x <= 1", "x 1 <= ", false)
+	compare(t, "solve(x = 1, x)", "", true)
+	compare(t, "1 = 2", "", true)
+	compare(t, "solve((x = 1), x, 0)", "x 1 = x 0 solve:3 ", false)
+	checkSyntaxError(t, "solve(x == 1 || x = 2, x, 0)", parser.CodeMisplacedEquation, 18, 19, "=",
+		"solve(x == 1 || x = 2, x, 0)\n                  ^")
+	checkSyntaxError(t, "x + (x = 1)", parser.CodeMisplacedEquation, 7, 8, "=", "x + (x = 1)\n       ^")
+	checkSyntaxError(t, "solve(x = 1, x, 0) = 2", parser.CodeMisplacedEquation, 19, 20, "=",
+		"solve(x = 1, x, 0) = 2\n                   ^")
+	checkSyntaxError(t, "solve(x, x, x = 0)", parser.CodeMisplacedEquation, 14, 15, "=", "solve(x, x, x = 0)\n              ^")
+}
+
+func TestIntegrate(t *testing.T) {
+	compare(t, "integrate(x^2, x, 0, 1)", "x 2 ^ x 0 1 integrate:4 ", false)
+	compare(t, "integrate(sin(x), x, 0, pi, 1e-6)", "x sin:1 x 0 pi 1e-6 integrate:5 ", false)
+	compare(t, "sum(k^2, k, 1, 100)", "k 2 ^ k 1 100 sum:4 ", false)
+	compare(t, "integrate(x, x, 0)", "", true)
+
+	for expr, bound := range map[string]bool{
+		"integrate(x^2, x, 0, 1)": true,
+		"solve(x = 1, x, 0)":      true,
+		"sum(k^2, k, 1, 100)":     true,
+		"sum(k^2, n, 1, 100)":     false,
+		"sum(1, 2, 3, 4)":         false,
+		"sum(k, k, 1)":            false,
+	} {
+		tree, err := parser.Parse(expr)
+		if err != nil {
+			t.Fatalf("error got '%s'", err)
+		}
+		if _, ok := tree.BoundVariable(); ok != bound {
+			t.Errorf("'%s' has bound variable: expected %t, got %t", expr, bound, ok)
+		}
+	}
+}
+
+func TestDerive(t *testing.T) {
+	for expr, expected := range map[string]string{
+		"x^2 * sin(x)":    "2*x*sin(x) + x^2*cos(x)",
+		"3*x^3 - 2*x + 7": "9*x^2 - 2",
+		"1/x":             "-1/x^2",
+		"e^(2*x)":         "2*e^(2*x)",
+		"a*x + b":         "a",
+		"sqrt(x)":         "1/(2*sqrt(x))",
+		"log(x, 10)":      "1/(x*log(10))",
+	} {
+		tree, err := parser.Parse(expr)
+		if err != nil {
+			t.Fatalf("error got '%s'", err)
+		}
+		derivative, err := parser.Derive(tree, "x")
+		if err != nil {
+			t.Errorf("derivative of '%s': error got '%s'", expr, err)
+			continue
+		}
+		if derivative.Infix() != expected {
+			t.Errorf("derivative of '%s' is not '%s', got '%s'", expr, expected, derivative.Infix())
+		}
+	}
+
+	tree, _ := parser.Parse("x mod 2")
+	if _, err := parser.Derive(tree, "x"); err == nil {
+		t.Errorf("expected error for 'x mod 2'")
+	}
+}
+
+func TestInfix(t *testing.T) {
+	for _, expr := range []string{"(1 - 2) - 3", "1 - (2 - 3)", "(2^3)^2", "2^3^2", "-x^2", "(-x)^2", "2*(-1)", "7 mod (2 + 1)", "if(x > 0, 1, 2)"} {
+		tree, err := parser.Parse(expr)
+		if err != nil {
+			t.Fatalf("error got '%s'", err)
+		}
+		again, err := parser.Parse(tree.Infix())
+		if err != nil {
+			t.Fatalf("infix '%s' of '%s': error got '%s'", tree.Infix(), expr, err)
+		}
+		if parser.FormatRPN(again.RPN()) != parser.FormatRPN(tree.RPN()) {
+			t.Errorf("infix '%s' of '%s' has other meaning", tree.Infix(), expr)
+		}
+	}
+}
+
+func TestSimplify(t *testing.T) {
+	for expr, expected := range map[string]string{
+		"2+3":                       "5",
+		"3+2":                       "5",
+		"x*1 + 0":                   "x",
+		"2*x + x":                   "3*x",
+		"b + a":                     "a + b",
+		"y * 2 * x * 3":             "6*x*y",
+		"x*y - y*x":                 "0",
+		"-x*y + y*x":                "0",
+		"-y*x - 1":                  "-x*y - 1",
+		"y * -x":                    "-x*y",
+		"2*x + 1 + x - 3":           "3*x - 2",
+		"--x":                       "x",
+		"if(1 < 2, x, y)":           "x",
+		"sin(0) + x":                "x",
+		"3 m + 20 cm":               "3*m + 20*cm",
+		"1 < 2":                     "1 < 2",
+		"now() + 0":                 "now() + 0",
+		"x*0 + y - y":               "0",
+		"0*(1/0)":                   "0*(1/0)",
+		"1/0 - 1/0":                 "1/0 - 1/0",
+		"inf - inf":                 "inf - inf",
+		"5 mod 0 * 0":               "5 mod 0*0",
+		"0*sqrt(-1)":                "0*sqrt(-1)",
+		"1e999 - 1e999":             "1e999 - 1e999",
+		"1e308*10 - 1e308*10":       "1e308*10 - 1e308*10",
+		"1e200*1e200 - 1e200*1e200": "1e200*1e200 - 1e200*1e200",
+		"2*(1/0) - 1/0":             "1/0",
+	} {
+		tree, err := parser.Parse(expr)
+		if err != nil {
+			t.Fatalf("error got '%s'", err)
+		}
+		if res := parser.Simplify(tree, op.Precision{Kind: op.Float64}).Infix(); res != expected {
+			t.Errorf("simplified '%s' is not '%s', got '%s'", expr, expected, res)
+		}
+	}
+
+	tree, _ := parser.Parse("1/3")
+	if res := parser.Simplify(tree, op.Precision{Kind: op.Rational}).Infix(); res != "1/3" {
+		t.Errorf("simplified rational '1/3' is not '1/3', got '%s'", res)
+	}
+}
+
+func TestUnits(t *testing.T) {
+	compare(t, "3 m + 20 cm", "3 m * 20 cm * + ", false)
+	compare(t, "60 km / 2 h", "60 km * 2 h * / ", false)
+	compare(t, "5 kg * 2 m/s^2", "5 kg * 2 m * * s 2 ^ / ", false)
+	compare(t, "-2 m^2", "2 u- m 2 ^ * ", false)
+	compare(t, "2 x", "", true)
+	compare(t, "m", "m ", false)
+
+	if v, err := (parser.Token{Kind: parser.NameToken, Text: "km"}).Decimal(); err != nil || v != "1 km" {
+		t.Errorf("value of km is not '1 km', got '%s' (%v)", v, err)
+	}
+}
+
+func TestDates(t *testing.T) {
+	compare(t, "2024-03-01 + 30d", "2024-03-01 30d + ", false)
+	compare(t, "now() - 2024-01-01", "now:0 2024-01-01 - ", false)
+	compare(t, "2024-01-31T10:30 + 1h30m * 2", "2024-01-31T10:30 1h30m 2 * + ", false)
+	compare(t, "2024-3-1", "2024 3 - 1 - ", false)
+	compare(t, "2min", "", true)
+	compare(t, "now(1)", "", true)
+	compare(t, "()", "", true)
+
+	tokens, err := parser.ParseToRPN("90m")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	if v, err := tokens[0].Decimal(); err != nil || v != "1h30m" {
+		t.Errorf("value is not '1h30m', got '%s' (%v)", v, err)
+	}
+	if _, err := parser.ParseNumber("30d"); err == nil {
+		t.Errorf("expected error for duration")
+	}
+	checkSyntaxError(t, "1 + 2024-02-30", parser.CodeInvalidNumber, 4, 5, "2024-02-30", "1 + 2024-02-30\n    ^^^^^^^^^^")
+}
+
+func TestComplexNumbers(t *testing.T) {
+	compare(t, "3 + 2i", "3 2i + ", false)
+	compare(t, "1.5e2i * i", "1.5e2i i * ", false)
+	compare(t, "sqrt(-4) - i", "4 u- sqrt:1 i - ", false)
+	compare(t, "2in", "", true)
+	compare(t, "2 i", "", true)
+
+	tokens, err := parser.ParseToRPN("2_0i")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	if v, err := tokens[0].Decimal(); err != nil || v != "20i" {
+		t.Errorf("value is not '20i', got '%s' (%v)", v, err)
+	}
+	if v, err := (parser.Token{Kind: parser.NameToken, Text: "i"}).Decimal(); err != nil || v != "1i" {
+		t.Errorf("value of i is not '1i', got '%s' (%v)", v, err)
+	}
+
+	if _, err := parser.ParseNumber("2i"); err == nil {
+		t.Errorf("expected error for imaginary number")
+	}
+}
+
+func checkNumber(t *testing.T, literal string, expected float64) {
+	v, err := parser.ParseNumber(literal)
+	if err != nil {
+		t.Errorf("'%s': error got '%s'", literal, err)
+	}
+	if v != expected {
+		t.Errorf("'%s': value is not %g, got %g", literal, expected, v)
+	}
+}
+
+func TestUnaryOperands(t *testing.T) {
+	compare(t, "-3 + 4", "3 u- 4 + ", false)
+	compare(t, "2 * (-1)", "2 1 u- * ", false)
+	compare(t, "2*-1", "2 1 u- * ", false)
+	compare(t, "-(1 + 2) * 3", "1 2 + u- 3 * ", false)
+	compare(t, "- -3", "3 u- u- ", false)
+	compare(t, "+2 - +1", "2 u+ 1 u+ - ", false)
+}
+
+func TestUnaryOperandErrors(t *testing.T) {
+	compare(t, "-", "", true)
+	compare(t, "2 -", "", true)
+	compare(t, "(-)", "", true)
+	compare(t, "2 u- 3", "", true)
+}
+
+func TestPow(t *testing.T) {
+	compare(t, "2 ^ 3 ^ 2", "2 3 2 ^ ^ ", false)
+	compare(t, "2 ** 3 * 4", "2 3 ^ 4 * ", false)
+	compare(t, "-2^2", "2 2 ^ u- ", false)
+	compare(t, "2^-1", "2 1 u- ^ ", false)
+	compare(t, "(2 ^ 3) ^ 2", "2 3 ^ 2 ^ ", false)
+	compare(t, "2 ^", "", true)
+}
+
+func TestPostfixOperands(t *testing.T) {
+	compare(t, "3! + 1", "3 ! 1 + ", false)
+	compare(t, "-3!", "3 ! u- ", false)
+	compare(t, "2 ^ 3!", "2 3 ! ^ ", false)
+	compare(t, "(1 + 2)! * 50%", "1 2 + ! 50 % * ", false)
+	compare(t, "3!!", "3 ! ! ", false)
+	compare(t, "!3", "3 not ", false)
+	compare(t, "2 + !", "", true)
+	compare(t, "3! 4", "", true)
+}
+
+func TestIntegerOperands(t *testing.T) {
+	compare(t, "7 % 3", "7 3 mod ", false)
+	compare(t, "7 mod 3 + 1", "7 3 mod 1 + ", false)
+	compare(t, "50% + 1", "50 % 1 + ", false)
+	compare(t, "7 %3", "7 3 mod ", false)
+	compare(t, "x % y", "x y mod ", false)
+	compare(t, "7 % (3)", "7 3 mod ", false)
+	compare(t, "7 % -3", "7 3 u- mod ", false)
+	compare(t, "7 % sqrt(4)", "7 4 sqrt:1 mod ", false)
+	compare(t, "50% - 1", "50 % 1 - ", false)
+	compare(t, "50% mod 3", "50 % 3 mod ", false)
+	compareMode(t, "x % y", "x y mod ", parser.Lenient)
+	compareMode(t, "50%(2)", "50 2 mod ", parser.Lenient)
+	compare(t, "7 // 2 * 3", "7 2 // 3 * ", false)
+	compare(t, "1 << 2 | 3", "1 2 << 3 | ", false)
+	compare(t, "1 | 2 xor 3 & 4", "1 2 3 4 & xor | ", false)
+	compare(t, "5 xor -3", "5 3 u- xor ", false)
+	compare(t, "8 >> 1 + 1", "8 1 1 + >> ", false)
+	compare(t, "xor 1", "", true)
+	compare(t, "1 mod", "", true)
+}
+
+func TestLogicOperands(t *testing.T) {
+	compare(t, "x > 3 && y <= 2", "x 3 > y 2 <= && ", false)
+	compare(t, "a || b && c", "a b c && || ", false)
+	compare(t, "1 + 2 == 3 or 0 != 1", "1 2 + 3 == 0 1 != || ", false)
+	compare(t, "!flag", "flag not ", false)
+	compare(t, "not x and 3! > 5", "x not 3 ! 5 > && ", false)
+	compare(t, "1 | 2 < 4", "1 2 | 4 < ", false)
+	compare(t, "1 <", "", true)
+	compare(t, "&& 1", "", true)
+}
+
+func TestConditions(t *testing.T) {
+	compare(t, "if(x > 0, x, -x)", "x 0 > x x u- if:3 ", false)
+	compare(t, "x > 0 ? x : -x", "x 0 > x x u- if:3 ", false)
+	compare(t, "a ? b : c ? d : e", "a b c d e if:3 if:3 ", false)
+	compare(t, "a ? b ? c : d : e", "a b c d if:3 e if:3 ", false)
+	compare(t, "(a ? 1 : 2) * 3", "a 1 2 if:3 3 * ", false)
+	compare(t, "max(a ? 1 : 2, 3)", "a 1 2 if:3 3 max:2 ", false)
+	compare(t, "a || b ? 1 + 2 : 3", "a b || 1 2 + 3 if:3 ", false)
+	compare(t, "if(1, 2)", "", true)
+	compare(t, "a ? b", "", true)
+	compare(t, "a : b", "", true)
+	compare(t, "(a ? b) : c", "", true)
+	compare(t, "max(a ? b, c)", "", true)
+	compare(t, "a ? : b", "", true)
+	checkSyntaxError(t, "1 + (a ? 2)", parser.CodeNoElse, 7, 8, "?", "1 + (a ? 2)\n       ^")
+}
+
+func TestFunctions(t *testing.T) {
+	compare(t, "max(1, sqrt(16), 3) + sin(0)", "1 16 sqrt:1 3 max:3 0 sin:1 + ", false)
+	compare(t, "-abs(2 - 5) * 2", "2 5 - abs:1 u- 2 * ", false)
+	compare(t, "log(8, 1 + 1)", "8 1 1 + log:2 ", false)
+	compare(t, "min((1), 2)", "1 2 min:2 ", false)
+	compare(t, "cos (0)^2", "0 cos:1 2 ^ ", false)
+}
+
+func TestFunctionErrors(t *testing.T) {
+	compare(t, "sin(1, 2)", "", true)
+	compare(t, "log(1, 2, 3)", "", true)
+	compare(t, "max()", "", true)
+	compare(t, "sin 1", "", true)
+	compare(t, "foo(1)", "", true)
+	compare(t, "(1, 2)", "", true)
+	compare(t, "1, 2", "", true)
+	compare(t, "max(1,)", "", true)
+	compare(t, "2 sin(1)", "", true)
+}
+
+func TestNames(t *testing.T) {
+	compare(t, "2 * pi * r", "2 pi * r * ", false)
+	compare(t, "-e ^ x_1", "e x_1 ^ u- ", false)
+	compare(t, "max(radius, 1)", "radius 1 max:2 ", false)
+	compare(t, "2 r", "", true)
+	compare(t, "sin + 1", "", true)
+
+	tokens, err := parser.ParseToRPN("tau + 1")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	if v, err := tokens[0].Number(); err != nil || v != 2*math.Pi {
+		t.Errorf("value of tau is not %g, got %g (%v)", 2*math.Pi, v, err)
+	}
+	if _, err := (parser.Token{Kind: parser.NameToken, Text: "r"}).Number(); err == nil {
+		t.Errorf("expected error for unknown name")
+	}
+	if got := parser.FormatRPN(parser.NumberTokens(-2.5)); got != "2.5 u- " {
+		t.Errorf("value is not '2.5 u- ', got '%s'", got)
+	}
+}
+
+func TestLenientMode(t *testing.T) {
+	compareMode(t, "2(3+4)", "2 3 4 + * ", parser.Lenient)
+	compareMode(t, "3pi", "3 pi * ", parser.Lenient)
+	compareMode(t, "(1 + 2)(3 - 4)", "1 2 + 3 4 - * ", parser.Lenient)
+	compareMode(t, "2 sin(0) x", "2 0 sin:1 * x * ", parser.Lenient)
+	compareMode(t, "x(1 + 2)", "x 1 2 + * ", parser.Lenient)
+	compareMode(t, "6 × 7 − 8 ÷ 2", "6 7 * 8 2 / - ", parser.Lenient)
+	compareMode(t, "√9 + 1", "9 sqrt:1 1 + ", parser.Lenient)
+	compareMode(t, "2√(4 + 5)", "2 4 5 + sqrt:1 * ", parser.Lenient)
+	compareMode(t, "−2 · 3", "2 u- 3 * ", parser.Lenient)
+	compareMode(t, "2 3", "", parser.Lenient)
+	compareMode(t, "2 -3", "2 3 - ", parser.Lenient)
+
+	compare(t, "2(3+4)", "", true)
+	compare(t, "6 × 7", "", true)
+	compare(t, "√9", "", true)
+	checkSyntaxError(t, "6 × 7", parser.CodeUnknownOperand, 2, 3, "×", "6 × 7\n  ^")
+}
+
+func compareMode(t *testing.T, expr, expectedExpr string, mode parser.Mode) {
+	tokens, err := parser.ParseToRPNMode(expr, mode)
+	if err != nil && expectedExpr != "" {
+		t.Errorf("'%s': error got '%s'", expr, err)
+	}
+	if err == nil && expectedExpr == "" {
+		t.Errorf("'%s': expected error", expr)
+	}
+	if val := parser.FormatRPN(tokens); val != expectedExpr {
+		t.Errorf("'%s': value is not '%s', got '%s'", expr, expectedExpr, val)
+	}
+}
+
+func TestParseTree(t *testing.T) {
+	tree, err := parser.Parse("max(1, -2) * 3!")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	if tree.Text != "*" || len(tree.Args) != 2 {
+		t.Fatalf("root is not '*' with 2 args, got '%s' with %d", tree.Text, len(tree.Args))
+	}
+	call := tree.Args[0]
+	if call.Kind != parser.FunctionToken || call.Text != "max" || len(call.Args) != 2 {
+		t.Errorf("first arg is not call of max with 2 args, got '%s'", call.Token)
+	}
+	if neg := call.Args[1]; neg.Text != "u-" || neg.Args[0].Text != "2" {
+		t.Errorf("second arg of max is not '-2', got '%s'", neg.Token)
+	}
+	if fact := tree.Args[1]; fact.Text != "!" || fact.Args[0].Kind != parser.NumberToken {
+		t.Errorf("second arg is not '3!', got '%s'", fact.Token)
+	}
+}
+
+func TestRPNRoundTrip(t *testing.T) {
+	tokens, err := parser.ParseToRPN("2 ^ 3 ^ 2 + log(8, 2)")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	data, err := json.Marshal(tokens)
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	var decoded []parser.Token
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	tree, err := parser.FromRPN(decoded)
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	if got, expected := parser.FormatRPN(tree.RPN()), "2 3 2 ^ ^ 8 2 log:2 + "; got != expected {
+		t.Errorf("value is not '%s', got '%s'", expected, got)
+	}
+	if _, err := parser.FromRPN(decoded[:len(decoded)-1]); err == nil {
+		t.Errorf("expected error for not finished expression")
+	}
+}
+
+func TestSyntaxError(t *testing.T) {
+	checkSyntaxError(t, "2 + * 3", parser.CodeMissingNumber, 4, 5, "*", "2 + * 3\n    ^")
+	checkSyntaxError(t, "(1 + 2", parser.CodeNotClosedParen, 0, 1, "(", "(1 + 2\n^")
+	checkSyntaxError(t, "1 + 2)", parser.CodeNoOpenParen, 5, 6, ")", "1 + 2)\n     ^")
+	checkSyntaxError(t, "2 +", parser.CodeMissingNumber, 3, 4, "", "2 +\n   ^")
+	checkSyntaxError(t, "1 + foo(2)", parser.CodeUnknownFunction, 4, 5, "foo", "1 + foo(2)\n    ^^^")
+	checkSyntaxError(t, "sin(1, 2)", parser.CodeWrongArgsCount, 0, 1, "sin", "sin(1, 2)\n^^^")
+	checkSyntaxError(t, "12 34", parser.CodeUnexpectedNumber, 3, 4, "34", "12 34\n   ^^")
+	checkSyntaxError(t, "«1» $ 2", parser.CodeUnknownOperand, 0, 1, "«", "«1» $ 2\n^")
+}
+
+func checkSyntaxError(t *testing.T, expr string, code parser.ErrorCode, offset, column int, token, snippet string) {
+	_, err := parser.ParseToRPN(expr)
+	var syntaxErr *parser.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		t.Errorf("'%s': error is not syntax error, got '%v'", expr, err)
+		return
+	}
+	if syntaxErr.Code != code {
+		t.Errorf("'%s': code is not '%s', got '%s'", expr, code, syntaxErr.Code)
+	}
+	if syntaxErr.Offset != offset || syntaxErr.Column != column {
+		t.Errorf("'%s': position is not %d:%d, got %d:%d", expr, offset, column, syntaxErr.Offset, syntaxErr.Column)
+	}
+	if syntaxErr.Token != token {
+		t.Errorf("'%s': token is not '%s', got '%s'", expr, token, syntaxErr.Token)
+	}
+	if syntaxErr.Snippet != snippet {
+		t.Errorf("'%s': snippet is not\n%s\ngot\n%s", expr, snippet, syntaxErr.Snippet)
+	}
+}
+
 func compare(t *testing.T, expr, expectedExpr string, expectErr bool) {
 	val, err := parser.ParseToPostfix(expr)
 	if err != nil && !expectErr {
@@ -1,87 +1,231 @@
 package parser
 
 import (
-	"bufio"
 	"fmt"
-	"strconv"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	op "github.com/XJIeI5/calculator/internal/operation"
 	"github.com/informitas/stack"
 )
 
-var (
-	errorNoChanges         error = fmt.Errorf("no changes")
-	errorNotAllNumbersUsed       = fmt.Errorf("not all numbers are involved in mathematical operations")
-	errorNotClosedParen          = fmt.Errorf("paren doesn't closed")
-	errorNoOpenParen             = fmt.Errorf("closed paren located before open paren")
-)
-
-func GetStringNumber(expr string) (res string) {
-	var (
-		result string
-	)
-
-	sc := bufio.NewScanner(strings.NewReader(expr))
-	sc.Split(bufio.ScanRunes)
-	for sc.Scan() {
-		if _, err := strconv.Atoi(sc.Text()); err == nil || sc.Text() == "." {
-			result += sc.Text()
+// GetStringName returns identifier expr starts with: letters, digits and underscores, first is not a digit
+func GetStringName(expr string) string {
+	for i, r := range expr {
+		if unicode.IsLetter(r) || r == '_' || (i > 0 && unicode.IsDigit(r)) {
 			continue
 		}
-		break
+		return expr[:i]
 	}
-
-	return result
+	return expr
 }
 
+// GetOperand returns the operand with the longest symbol or alias expr starts with
 func GetOperand(expr string) op.Operand {
-	sc := bufio.NewScanner(strings.NewReader(expr))
-	sc.Split(bufio.ScanRunes)
-	var sym string
-	for sc.Scan() {
-		if sc.Text() == " " {
-			break
-		}
-		if _, err := strconv.Atoi(sc.Text()); err == nil {
-			break
+	oper, _ := matchOperand(expr, Strict)
+	return oper
+}
+
+// matchOperand returns the operand expr starts with and the text it is written by
+func matchOperand(expr string, mode Mode) (op.Operand, string) {
+	var (
+		res    op.Operand
+		symbol string
+	)
+	check := func(oper op.Operand, sym string) {
+		if strings.HasPrefix(expr, sym) && len(sym) > len(symbol) {
+			res, symbol = oper, sym
 		}
-		sym += sc.Text()
 	}
 	for _, oper := range op.Operands {
-		if sym == oper.Symbol() {
-			return oper
+		check(oper, oper.Symbol())
+	}
+	for alias, oper := range op.Aliases {
+		check(oper, alias)
+	}
+	if mode&UnicodeSymbols != 0 {
+		for alias, oper := range op.UnicodeAliases {
+			check(oper, alias)
 		}
 	}
-	return nil
+	return res, symbol
 }
 
-func addTo(res, parsed string) string {
-	return fmt.Sprintf("%s%s ", res, parsed[:len(parsed)-1])
+// isOperandStart returns true if operand starts a new operand: open paren, prefix operand or root sign
+func isOperandStart(operand op.Operand) bool {
+	switch t := operand.(type) {
+	case op.OrderOperand:
+		return t.IsStart()
+	case op.FunctionOperand:
+		return true
+	default:
+		return false
+	}
 }
 
+// isWordOperand returns true if name is written operand which isn't a function: "xor", "mod"
+func isWordOperand(name string) bool {
+	oper, ok := op.FindOperand(name)
+	if !ok {
+		return false
+	}
+	_, isFunction := oper.(op.FunctionOperand)
+	return !isFunction
+}
+
+// startsOperand returns true if expr starts with value a binary operand can be applied to, spaces before it
+// are skipped: number, name, function, open paren or sign written together with its value.
+// "%" before it is mod, otherwise percent: "7 % x", "7 % (3)", "7 % -3" are mod, "50% + 1" is percent
+func startsOperand(expr string, mode Mode) bool {
+	expr = strings.TrimLeft(expr, " ")
+	if expr == "" {
+		return false
+	}
+	if r, _ := utf8.DecodeRuneInString(expr); unicode.IsDigit(r) || r == '.' || r == '[' {
+		return true
+	}
+	if name := GetStringName(expr); name != "" {
+		return !isWordOperand(name)
+	}
+	operand, symbol := matchOperand(expr, mode)
+	if operand == nil {
+		return false
+	}
+	if _, ok := op.PrefixForms[operand]; ok {
+		rest := expr[len(symbol):]
+		return !strings.HasPrefix(rest, " ") && startsOperand(rest, mode)
+	}
+	return isOperandStart(operand)
+}
+
+// ParseToPostfix returns infix expression in reverse polish notation separated by spaces: "2 2 * 1 + "
 func ParseToPostfix(infixExpr string) (string, error) {
+	tokens, err := ParseToRPN(infixExpr)
+	if err != nil {
+		return "", err
+	}
+	return FormatRPN(tokens), nil
+}
+
+// paren describes open paren which is not closed yet
+type paren struct {
+	offset int
+	// argsCount is amount of args for function call paren and 0 for others
+	argsCount int
+	// fn is called function and fnOffset is offset of its name
+	fn       op.FunctionOperand
+	fnOffset int
+}
+
+// Mode is a set of flags which turn on additional syntax
+type Mode uint
+
+const (
+	// ImplicitMult inserts multiplication between operands written together: "2(3 + 4)", "3pi", "2√9".
+	// two numbers in a row are still an error
+	ImplicitMult Mode = 1 << iota
+	// UnicodeSymbols allows to write operands by symbols "×", "·", "÷", "−" and "√"
+	UnicodeSymbols
+)
+
+const (
+	Strict  Mode = 0
+	Lenient Mode = ImplicitMult | UnicodeSymbols
+)
+
+var modeNames = map[string]Mode{
+	"strict":  Strict,
+	"lenient": Lenient,
+}
+
+// ModeByName returns mode by name "strict" or "lenient"
+func ModeByName(name string) (Mode, bool) {
+	mode, ok := modeNames[name]
+	return mode, ok
+}
+
+// ParseToRPN returns tokens of infix expression in reverse polish notation.
+// returned error is *SyntaxError
+func ParseToRPN(infixExpr string) ([]Token, error) {
+	return ParseToRPNMode(infixExpr, Strict)
+}
+
+// ParseToRPNMode is ParseToRPN with additional syntax turned on by mode
+func ParseToRPNMode(infixExpr string, mode Mode) ([]Token, error) {
 	var (
-		res            string
+		res            []Token
 		digitsInAction int
 		skip           int
+		// waitNumber is true when a number, an open paren or a prefix operand must follow
+		waitNumber = true
+		// fnOffset is offset of the last parsed function name
+		fnOffset int
+		// lastIsNumber is true when the last parsed token is a number literal
+		lastIsNumber bool
+		// lastIsCall is true when the last parsed token is a paren of function call: "now("
+		lastIsCall bool
 	)
 	s := stack.NewStack[op.Operand]()
+	parens := stack.NewStack[paren]()
+	// thens are offsets of '?' which have no ':' yet
+	thens := stack.NewStack[int]()
+	// equations are offsets of '=' which aren't written to output yet, they are popped in the same order
+	equations := stack.NewStack[int]()
 
-	consumeAll := func() (string, error) {
-		var consumed string
+	fail := func(offset int, token string, err error) ([]Token, error) {
+		return nil, newSyntaxError(infixExpr, offset, token, err)
+	}
+	emit := func(oper op.Operand) {
+		switch oper.(type) {
+		case op.BinaryOperand:
+			digitsInAction--
+		case op.FunctionOperand:
+			// function is in stack without parens only if it is written by root sign: "√9"
+			res = append(res, Token{Kind: FunctionToken, Text: oper.Symbol(), Args: 1})
+			return
+		case op.ConditionOperand:
+			// "cond ? a : b" is written like "if(cond, a, b)"
+			res = append(res, Token{Kind: FunctionToken, Text: op.If.Symbol(), Args: 3})
+			return
+		}
+		token := Token{Kind: OperandToken, Text: oper.Symbol()}
+		if oper == op.Equation {
+			token.Offset, _ = equations.Pop()
+		}
+		res = append(res, token)
+	}
+	insertMult := func(mult op.BinaryOperand) {
+		parsedOpers, _ := parseBinaryOperand(mult, s)
+		for _, oper := range parsedOpers {
+			emit(oper)
+		}
+		waitNumber = true
+	}
+	// popUntilStop writes operands to output until open paren or '?', which is left in stack and returned
+	popUntilStop := func() op.Operand {
 		for !s.IsEmpty() {
-			oper, _ := s.Pop()
-			if _, ok := oper.(op.OrderOperand); ok {
-				return consumed, errorNotClosedParen
-			}
-			if _, ok := oper.(op.BinaryOperand); ok {
-				digitsInAction--
+			oper, _ := s.Top()
+			switch t := oper.(type) {
+			case op.OrderOperand:
+				return t
+			case op.ConditionOperand:
+				if t.IsThen() {
+					return t
+				}
 			}
-			consumed = fmt.Sprintf("%s%s ", consumed, oper.Symbol())
+			s.Pop()
+			emit(oper)
 		}
-		return consumed, nil
+		return nil
+	}
+	// checkStop returns error if stop returned by popUntilStop isn't open paren
+	checkStop := func(stop op.Operand, offset int, token string, err error) ([]Token, error) {
+		if _, ok := stop.(op.ConditionOperand); ok {
+			t, _ := thens.Top()
+			return fail(t, op.Then.Symbol(), errorNoElse)
+		}
+		return fail(offset, token, err)
 	}
 
 	for i, r := range infixExpr {
@@ -93,100 +237,353 @@ func ParseToPostfix(infixExpr string) (string, error) {
 		if r == ' ' {
 			continue
 		}
-		if unicode.IsDigit(r) { // PARSE DIGIT
-			num := GetStringNumber(infixExpr[i:])
-			res = addTo(res, num+" ")
-			skip = len(num) - 1
+		prevIsNumber, prevIsCall := lastIsNumber, lastIsCall
+		lastIsNumber, lastIsCall = false, false
+		canInsertMult := !waitNumber && mode&ImplicitMult != 0
+
+		if literal, elements := lexListArg(infixExpr[i:], s, parens); r == '[' && waitNumber && elements != nil { // PARSE LIST
+			p, _ := parens.Pop()
+			p.argsCount += len(elements) - 1
+			parens.Push(p)
+			for _, el := range elements {
+				res = append(res, listElementTokens(el, i)...)
+			}
+			skip = utf8.RuneCountInString(literal) - 1
+			digitsInAction += len(elements)
+			waitNumber = false
+		} else if unicode.IsDigit(r) || r == '.' || r == '[' { // PARSE DIGIT OR INTERVAL
+			num, text, err := lexValue(infixExpr[i:])
+			if err != nil {
+				return fail(i, num, fmt.Errorf("%w: %s", errorInvalidNumber, err))
+			}
+			// interval is multiplied like paren: "2[1, 2]"
+			if canInsertMult && (!prevIsNumber || r == '[') {
+				insertMult(op.Mult)
+			}
+			if !waitNumber {
+				return fail(i, num, errorUnexpectedNumber)
+			}
+			res = append(res, Token{Kind: NumberToken, Text: text, Offset: i})
+			skip = utf8.RuneCountInString(num) - 1
 			digitsInAction++
+			waitNumber = false
+			lastIsNumber = true
+		} else if (unicode.IsLetter(r) || r == '_') && !isWordOperand(GetStringName(infixExpr[i:])) { // PARSE NAME OR FUNCTION
+			name := GetStringName(infixExpr[i:])
+			// unit after value is a multiplier in any mode: "3 m", "2 kg"
+			if !waitNumber && op.IsUnit(name) {
+				insertMult(op.UnitMult)
+			} else if canInsertMult {
+				insertMult(op.Mult)
+			}
+			if !waitNumber {
+				return fail(i, name, errorUnexpectedNumber)
+			}
+			skip = utf8.RuneCountInString(name) - 1
+			fn, isFunction := op.FindFunction(name)
+			isCall := strings.HasPrefix(strings.TrimLeft(infixExpr[i+len(name):], " "), op.OpenParen.Symbol())
+			// with implicit multiplication paren after name which isn't a function is a multiplier: "x(1 + 2)"
+			if !isCall || (!isFunction && mode&ImplicitMult != 0) {
+				if isFunction {
+					return fail(i, name, errorCallWithoutParens)
+				}
+				// value of name is resolved while calculating
+				res = append(res, Token{Kind: NameToken, Text: name, Offset: i})
+				digitsInAction++
+				waitNumber = false
+				continue
+			}
+			if !isFunction {
+				return fail(i, name, errorUnknownFunction)
+			}
+			fnOffset = i
+			s.Push(fn)
+		} else if r == ',' { // PARSE COMMA
+			if waitNumber {
+				return fail(i, ",", errorMissingNumber)
+			}
+			if stop := popUntilStop(); stop != op.OpenParen {
+				return checkStop(stop, i, ",", errorCommaOutsideCall)
+			}
+			p, _ := parens.Pop()
+			if p.fn == nil {
+				return fail(i, ",", errorCommaOutsideCall)
+			}
+			p.argsCount++
+			parens.Push(p)
+			waitNumber = true
 		} else { // PARSE OPERAND
-			operand := GetOperand(infixExpr[i:])
+			operand, symbol := matchOperand(infixExpr[i:], mode)
 			if operand == nil {
-				return "", fmt.Errorf("unknown operand %c", r)
+				return fail(i, string(r), errorUnknownOperand)
+			}
+			skip = utf8.RuneCountInString(symbol) - 1
+			if prefix, ok := op.PrefixForms[operand]; ok && waitNumber {
+				operand = prefix
+			}
+			if infix, ok := op.InfixForms[operand]; ok && !waitNumber && startsOperand(infixExpr[i+len(symbol):], mode) {
+				operand = infix
+			}
+			if canInsertMult && isOperandStart(operand) {
+				insertMult(op.Mult)
 			}
-			skip = len(operand.Symbol()) - 1
 			switch t := operand.(type) {
-			case op.MathOperand:
-				parsedOpers, err := parseMathOperand(operand, s)
-				if err == errorNoChanges {
-					continue
+			case op.FunctionOperand: // root sign: "√9"
+				if !waitNumber {
+					return fail(i, symbol, errorPrefixAfterNumber)
 				}
+				fnOffset = i
+				s.Push(t)
+			case op.MathOperand:
+				parsedOpers, err := parseMathOperand(t, s, waitNumber)
 				if err != nil {
-					return "", err
+					return fail(i, symbol, err)
 				}
-				res = fmt.Sprintf("%s%s ", res, parsedOpers)
-				if _, ok := operand.(op.BinaryOperand); ok {
-					digitsInAction--
+				if t == op.Equation {
+					equations.Push(i)
 				}
-			case op.OrderOperand:
-				if t.IsStart() {
-					s.Push(t)
+				for _, oper := range parsedOpers {
+					emit(oper)
+				}
+				switch t.(type) {
+				case op.BinaryOperand, op.PrefixOperand:
+					waitNumber = true
+				default:
+					waitNumber = false
+				}
+			case op.ConditionOperand:
+				if waitNumber {
+					return fail(i, symbol, errorMissingNumber)
+				}
+				if t.IsThen() {
+					parsedOpers, _ := parseBinaryOperand(t, s)
+					for _, oper := range parsedOpers {
+						emit(oper)
+					}
+					thens.Push(i)
 				} else {
-					if s.Size() <= 0 {
-						return "", errorNoOpenParen
+					if stop := popUntilStop(); stop != op.Then {
+						return fail(i, symbol, errorNoThen)
 					}
-					consumed, err := consumeAll()
-					if err != nil && err != errorNotClosedParen {
-						return "", err
+					s.Pop()
+					thens.Pop()
+					s.Push(t)
+				}
+				// "?" and ":" together take three numbers and give one
+				digitsInAction--
+				waitNumber = true
+			case op.OrderOperand:
+				if t.IsStart() {
+					if !waitNumber {
+						return fail(i, symbol, errorUnexpectedNumber)
 					}
-					if err != errorNotClosedParen {
-						return "", errorNoOpenParen
+					p := paren{offset: i}
+					top, _ := s.Top()
+					if fn, ok := top.(op.FunctionOperand); ok {
+						p.argsCount, p.fn, p.fnOffset = 1, fn, fnOffset
+						lastIsCall = true
 					}
-					res = addTo(res, consumed)
+					parens.Push(p)
+					s.Push(t)
+					continue
 				}
+				// function can be called without args: "now()"
+				if waitNumber && !prevIsCall {
+					return fail(i, symbol, errorMissingNumber)
+				}
+				if stop := popUntilStop(); stop != op.OpenParen {
+					return checkStop(stop, i, symbol, errorNoOpenParen)
+				}
+				s.Pop()
+				p, _ := parens.Pop()
+				if prevIsCall {
+					p.argsCount = 0
+					waitNumber = false
+				}
+				if p.fn == nil {
+					continue
+				}
+				s.Pop()
+				if err := op.CheckArity(p.fn, p.argsCount); err != nil {
+					return fail(p.fnOffset, p.fn.Symbol(), fmt.Errorf("%w: %s", errorWrongArgsCount, err))
+				}
+				res = append(res, Token{Kind: FunctionToken, Text: p.fn.Symbol(), Args: p.argsCount, Offset: p.fnOffset})
+				digitsInAction -= p.argsCount - 1
 			}
 		}
 	}
-	consumed, err := consumeAll()
-	if err != nil {
-		return "", err
+	if waitNumber {
+		return fail(len(infixExpr), "", errorMissingNumber)
+	}
+	for !s.IsEmpty() {
+		oper, _ := s.Pop()
+		if _, ok := oper.(op.OrderOperand); ok {
+			p, _ := parens.Pop()
+			return fail(p.offset, oper.Symbol(), errorNotClosedParen)
+		}
+		if oper == op.Then {
+			t, _ := thens.Pop()
+			return fail(t, oper.Symbol(), errorNoElse)
+		}
+		emit(oper)
 	}
-	res = addTo(res, consumed)
 
 	if digitsInAction != 1 {
-		return "", errorNotAllNumbersUsed
+		return fail(len(infixExpr), "", errorNotAllNumbersUsed)
+	}
+	root, err := FromRPN(res)
+	if err != nil {
+		return fail(len(infixExpr), "", errorNotAllNumbersUsed)
+	}
+	if equation := misplacedEquation(root, false); equation != nil {
+		return fail(equation.Offset, equation.Text, errorMisplacedEquation)
 	}
 
 	return res, nil
 }
 
-func parseMathOperand(operand op.Operand, operStack *stack.Stack[op.Operand]) (string, error) {
+// misplacedEquation returns equation which isn't the whole first arg of solve: "solve(x == 1 || x = 2, x, 0)",
+// allowed is true if node is the first arg of solve
+func misplacedEquation(node *Node, allowed bool) *Node {
+	if operand, _ := node.Operand(); operand == op.Equation && !allowed {
+		return node
+	}
+	for i, arg := range node.Args {
+		firstOfSolve := i == 0 && node.Kind == FunctionToken && node.Text == op.Solve.Symbol()
+		if res := misplacedEquation(arg, firstOfSolve); res != nil {
+			return res
+		}
+	}
+	return nil
+}
+
+// lexListArg returns list literal expr starts with and its elements if list is the single arg
+// of aggregate function: "sum([1, 2, 3])", otherwise elements are nil and "[1, 2]" is an interval:
+// "min([1, 2], [3, 4])" is minimum of intervals
+func lexListArg(expr string, operStack *stack.Stack[op.Operand], parens *stack.Stack[paren]) (string, []string) {
+	if !strings.HasPrefix(expr, "[") {
+		return "", nil
+	}
+	p, err := parens.Top()
+	if err != nil {
+		return "", nil
+	}
+	if _, ok := p.fn.(op.AggregateOperand); !ok || p.argsCount != 1 {
+		return "", nil
+	}
+	// operand between paren and list means that list is a part of arg: "sum(2 * [1, 2])"
+	if top, _ := operStack.Top(); top != op.OpenParen {
+		return "", nil
+	}
+	literal, elements, err := LexList(expr)
+	if err != nil {
+		return "", nil
+	}
+	next := strings.TrimLeft(expr[len(literal):], " ")
+	if !strings.HasPrefix(next, op.ClosedParen.Symbol()) {
+		return "", nil
+	}
+	return literal, elements
+}
+
+// listElementTokens returns tokens of list element, negative element is written as number and unary minus
+func listElementTokens(element string, offset int) []Token {
+	number := strings.TrimLeft(element, "+-")
+	tokens := []Token{{Kind: NumberToken, Text: number, Offset: offset}}
+	if strings.HasPrefix(element, "-") {
+		tokens = append(tokens, Token{Kind: OperandToken, Text: op.Neg.Symbol()})
+	}
+	return tokens
+}
+
+// lexValue returns number, interval, date or duration literal expr starts with and its text in token
+func lexValue(expr string) (string, string, error) {
+	for _, literal := range []string{LexDate(expr), LexDuration(expr)} {
+		if literal != "" {
+			_, err := DecimalNumber(literal)
+			return literal, literal, err
+		}
+	}
+	if !strings.HasPrefix(expr, "[") {
+		num, err := LexNumber(expr)
+		return num, num, err
+	}
+	lex := LexInterval
+	if isMatrixLiteral(expr) {
+		lex = LexMatrix
+	}
+	literal, text, err := lex(expr)
+	if err != nil {
+		return literal, "", err
+	}
+	_, err = DecimalNumber(text)
+	return literal, text, err
+}
+
+// parseMathOperand pushes operand to operStack and returns operands which must be written to output before it
+func parseMathOperand(operand op.MathOperand, operStack *stack.Stack[op.Operand], waitNumber bool) ([]op.Operand, error) {
 	switch t := operand.(type) {
 	case op.BinaryOperand:
+		if waitNumber {
+			return nil, errorMissingNumber
+		}
 		return parseBinaryOperand(t, operStack)
 	case op.PostfixOperand:
+		if waitNumber {
+			return nil, errorMissingNumber
+		}
 		return parsePostfixOperand(t, operStack)
 	case op.PrefixOperand:
+		if !waitNumber {
+			return nil, errorPrefixAfterNumber
+		}
 		return parsePrefixOperand(t, operStack)
 	default:
-		return "", fmt.Errorf("operand '%s' is not defined", operand.Symbol())
+		return nil, errorUnknownOperand
 	}
 }
 
-func parseBinaryOperand(operand op.BinaryOperand, operStack *stack.Stack[op.Operand]) (string, error) {
-	var operands string
+func parseBinaryOperand(operand op.Operand, operStack *stack.Stack[op.Operand]) ([]op.Operand, error) {
+	var operands []op.Operand
+	info := op.OperationTable[operand]
 
-	for {
+	// left associative operands are calculated from left to right: 1 - 2 - 3 = (1 - 2) - 3,
+	// right associative from right to left: 2 ^ 3 ^ 2 = 2 ^ (3 ^ 2)
+	for operStack.Size() > 0 {
 		peek, _ := operStack.Top()
-		if operStack.Size() > 0 && op.OperationPriority[peek] >= op.OperationPriority[operand] {
-			oper, _ := operStack.Pop()
-			operands = fmt.Sprintf("%s%s ", operands, oper.Symbol())
-			continue
+		peekInfo := op.OperationTable[peek]
+		if peekInfo.Priority < info.Priority || (peekInfo.Priority == info.Priority && info.Assoc == op.RightAssoc) {
+			break
 		}
-		break
+		oper, _ := operStack.Pop()
+		operands = append(operands, oper)
 	}
 
-	// operands := getOperandsWithHigherPriority(operStack, op.OperationPriority[operand])
 	operStack.Push(operand)
-	if len(operands) != 0 {
-		return operands[:len(operands)-1], nil
-	}
-	return "", errorNoChanges
+	return operands, nil
 }
 
-func parsePostfixOperand(operand op.PostfixOperand, operStack *stack.Stack[op.Operand]) (string, error) {
-	return "", fmt.Errorf("postfix operand parsing not implemented")
+// parsePostfixOperand applies operand to the number right before it: it is written to output at once
+// after operands with higher priority, so "-3!" is "-(3!)"
+func parsePostfixOperand(operand op.PostfixOperand, operStack *stack.Stack[op.Operand]) ([]op.Operand, error) {
+	var operands []op.Operand
+	info := op.OperationTable[operand]
+
+	for operStack.Size() > 0 {
+		peek, _ := operStack.Top()
+		if op.OperationTable[peek].Priority <= info.Priority {
+			break
+		}
+		oper, _ := operStack.Pop()
+		operands = append(operands, oper)
+	}
+
+	return append(operands, operand), nil
 }
 
-func parsePrefixOperand(operand op.PrefixOperand, operStack *stack.Stack[op.Operand]) (string, error) {
-	return "", fmt.Errorf("prefix operand parsing not implemented")
+// parsePrefixOperand only delays the operand: it is applied to the whole number after it,
+// so nothing is popped from operStack
+func parsePrefixOperand(operand op.PrefixOperand, operStack *stack.Stack[op.Operand]) ([]op.Operand, error) {
+	operStack.Push(operand)
+	return nil, nil
 }
@@ -0,0 +1,430 @@
+package parser
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+// Simplify returns tree in canonical form, so the same expression written differently has the same tree:
+// "3 + 2" and "2 + 3" are "5", "x*1 + 0" is "x", "2*x + x" is "3*x", "b + a" is "a + b".
+// operations with real number args are calculated with precision p the same way as by computation
+// servers, results which can't be written as number literal aren't calculated: "1/3" with rational
+// precision. booleans, aggregate functions and now() aren't calculated. terms and factors are
+// reordered only if all values are real numbers or names, because "3 m + 20 cm" is in m and
+// product of matrices depends on order
+func Simplify(node *Node, p op.Precision) *Node {
+	if len(node.Args) == 0 {
+		return node
+	}
+	args := make([]*Node, len(node.Args))
+	for i, arg := range node.Args {
+		args[i] = Simplify(arg, p)
+	}
+	node = &Node{Token: node.Token, Args: args}
+	operand, err := node.Operand()
+	if err != nil {
+		return node
+	}
+	if res, ok := foldConstant(node, operand, p); ok {
+		return res
+	}
+
+	switch operand {
+	case op.If:
+		if cond, ok := conditionValue(args[0], p); ok {
+			if isTrue, err := cond.IsTrue(); err == nil {
+				return args[op.If.Choose(isTrue)]
+			}
+		}
+	case op.Pos:
+		return args[0]
+	case op.Neg:
+		if args[0].isNeg() {
+			return args[0].Args[0]
+		}
+	case op.Add, op.Sub:
+		if isReal(node) {
+			if res, ok := simplifySum(node, p); ok {
+				return res
+			}
+		}
+	case op.Mult:
+		if isReal(node) {
+			if res, ok := simplifyProduct(node, p); ok {
+				return res
+			}
+		}
+		if isNumber(args[1], 1) {
+			return args[0]
+		}
+		if isNumber(args[0], 1) {
+			return args[1]
+		}
+	case op.Div, op.Pow:
+		if isNumber(args[1], 1) {
+			return args[0]
+		}
+	}
+	return node
+}
+
+// foldConstant returns number calculated by operand if all its args are real numbers.
+// booleans aren't calculated, because their results are shown as true and false
+func foldConstant(node *Node, operand op.Operand, p op.Precision) (*Node, bool) {
+	switch operand.(type) {
+	case op.BooleanOperand, op.ConditionalOperand, op.AggregateOperand, op.ClockOperand:
+		return nil, false
+	}
+	res, ok := calculate(node, operand, p)
+	if !ok {
+		return nil, false
+	}
+	return literalNode(res)
+}
+
+// conditionValue returns value of condition of if: number or comparison of numbers
+func conditionValue(node *Node, p op.Precision) (op.Number, bool) {
+	if v, ok := constantValue(node); ok {
+		return v, true
+	}
+	operand, err := node.Operand()
+	if err != nil {
+		return "", false
+	}
+	switch operand.(type) {
+	case op.ConditionalOperand, op.AggregateOperand, op.ClockOperand:
+		return "", false
+	}
+	return calculate(node, operand, p)
+}
+
+// calculate returns value of operand if all its args are real numbers
+func calculate(node *Node, operand op.Operand, p op.Precision) (op.Number, bool) {
+	values := make([]op.Number, len(node.Args))
+	for i, arg := range node.Args {
+		v, ok := constantValue(arg)
+		if !ok {
+			return "", false
+		}
+		values[i] = v
+	}
+	var (
+		res op.Number
+		err error
+	)
+	switch t := operand.(type) {
+	case op.FunctionOperand:
+		res, err = p.ExecFunction(t, values...)
+	case op.UnaryOperand:
+		res, err = p.ExecUnary(t, values[0])
+	case op.BinaryOperand:
+		res, err = p.ExecBinary(t, values[0], values[1])
+	default:
+		return "", false
+	}
+	// errors are returned by computation servers while calculating
+	return res, err == nil
+}
+
+// constantValue returns value of real number or its unary minus: "2", "-2"
+func constantValue(node *Node) (op.Number, bool) {
+	if node.isNeg() {
+		v, ok := constantValue(node.Args[0])
+		if !ok || strings.HasPrefix(string(v), "-") {
+			return "", false
+		}
+		return "-" + v, true
+	}
+	if node.Kind != NumberToken {
+		return "", false
+	}
+	digits, err := DecimalNumber(node.Text)
+	if err != nil || !isRealNumber(op.Number(digits)) {
+		return "", false
+	}
+	return op.Number(digits), true
+}
+
+// literalNode returns tree of number if it can be written as number literal, negative number
+// is written as unary minus of number
+func literalNode(n op.Number) (*Node, bool) {
+	digits := strings.TrimPrefix(string(n), "-")
+	if lexed, err := LexNumber(digits); err != nil || lexed != digits || !isRealNumber(op.Number(digits)) {
+		return nil, false
+	}
+	node := &Node{Token: Token{Kind: NumberToken, Text: digits}}
+	if strings.HasPrefix(string(n), "-") {
+		return operandNode(op.Neg, node), true
+	}
+	return node, true
+}
+
+func isRealNumber(n op.Number) bool {
+	return !n.IsComplex() && !n.IsInterval() && !n.IsMatrix() && !n.IsDate() && !n.IsDuration() && !n.IsQuantity()
+}
+
+// isReal returns true if all values of tree are real numbers or names which aren't units
+func isReal(node *Node) bool {
+	switch node.Kind {
+	case NumberToken:
+		_, ok := constantValue(node)
+		return ok
+	case NameToken:
+		return !op.IsUnit(node.Text) && node.Text != op.ImaginaryUnit
+	}
+	if node.Kind == FunctionToken && node.Text == op.Now.Symbol() {
+		return false
+	}
+	for _, arg := range node.Args {
+		if !isReal(arg) {
+			return false
+		}
+	}
+	return true
+}
+
+// isFinite returns true if node is a number or a name which value is known to be finite: "2", "-x", "pi".
+// "inf", "1e999" and operations which weren't calculated, like "1/0", aren't finite, so "x - x" and "x*0"
+// are 0 only for finite x, otherwise error or NaN is returned by computation server
+func isFinite(node *Node) bool {
+	if node.isNeg() {
+		return isFinite(node.Args[0])
+	}
+	switch node.Kind {
+	case NumberToken:
+		v, ok := constantValue(node)
+		if !ok {
+			return false
+		}
+		_, err := strconv.ParseFloat(string(v), 64)
+		return err == nil
+	case NameToken:
+		v, ok := op.Constants[node.Text]
+		return !ok || !math.IsInf(v, 0)
+	}
+	return false
+}
+
+// allConstant returns true if all nodes are real numbers
+func allConstant(nodes []*Node) bool {
+	for _, node := range nodes {
+		if _, ok := constantValue(node); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// allFinite returns true if all nodes are finite
+func allFinite(nodes []*Node) bool {
+	for _, node := range nodes {
+		if !isFinite(node) {
+			return false
+		}
+	}
+	return true
+}
+
+// term is a summand written as coefficient multiplied by the rest: "2*x", rest of number is nil
+type term struct {
+	coefficient op.Number
+	rest        *Node
+}
+
+// simplifySum adds up coefficients of terms with the same rest and numbers: "2*x + 1 + x - 3" is "3*x - 2".
+// terms are sorted, number is the last
+func simplifySum(node *Node, p op.Precision) (*Node, bool) {
+	var (
+		terms    = make(map[string]*term)
+		constant = op.Number("0")
+	)
+	for _, t := range summands(node, false) {
+		coefficient, rest := splitCoefficient(t.node)
+		if rest == nil && !isFinite(t.node) {
+			return nil, false
+		}
+		// product of numbers which isn't folded overflows or can't be written as literal:
+		// "1e308*10 - 1e308*10" is inf - inf, it isn't 0
+		if rest != nil && allConstant(factors(rest)) {
+			return nil, false
+		}
+		if t.negative {
+			var err error
+			if coefficient, err = p.ExecUnary(op.Neg, coefficient); err != nil {
+				return nil, false
+			}
+		}
+		var (
+			sum = &constant
+			err error
+		)
+		if rest != nil {
+			key := canonicalKey(rest)
+			if _, ok := terms[key]; !ok {
+				terms[key] = &term{coefficient: "0", rest: rest}
+			}
+			sum = &terms[key].coefficient
+		}
+		if *sum, err = p.ExecBinary(op.Add, *sum, coefficient); err != nil {
+			return nil, false
+		}
+	}
+
+	keys := make([]string, 0, len(terms))
+	for key := range terms {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var res *Node
+	appendTerm := func(coefficient op.Number, rest *Node) bool {
+		// "1/0 - 1/0" isn't 0, it is kept to return error
+		if isTrue, err := coefficient.IsTrue(); err != nil || !isTrue {
+			return err == nil && (rest == nil || allFinite(factors(rest)))
+		}
+		negative := strings.HasPrefix(string(coefficient), "-")
+		value, ok := literalNode(op.Number(strings.TrimPrefix(string(coefficient), "-")))
+		if !ok {
+			return false
+		}
+		if rest != nil {
+			value = multNodes(value, rest)
+		}
+		switch {
+		case res == nil && negative:
+			list := factors(value)
+			list[0] = negNode(list[0])
+			res = productNode(list)
+		case res == nil:
+			res = value
+		case negative:
+			res = operandNode(op.Sub, res, value)
+		default:
+			res = operandNode(op.Add, res, value)
+		}
+		return true
+	}
+	for _, key := range keys {
+		if !appendTerm(terms[key].coefficient, terms[key].rest) {
+			return nil, false
+		}
+	}
+	if !appendTerm(constant, nil) {
+		return nil, false
+	}
+	if res == nil {
+		return numberNode(0), true
+	}
+	return res, true
+}
+
+// summand is a term of sum which is added or subtracted
+type summand struct {
+	node     *Node
+	negative bool
+}
+
+// summands returns terms of chain of additions, subtractions and unary minuses
+func summands(node *Node, negative bool) []summand {
+	switch {
+	case node.Kind == OperandToken && node.Text == op.Add.Symbol():
+		return append(summands(node.Args[0], negative), summands(node.Args[1], negative)...)
+	case node.Kind == OperandToken && node.Text == op.Sub.Symbol():
+		return append(summands(node.Args[0], negative), summands(node.Args[1], !negative)...)
+	case node.isNeg():
+		return summands(node.Args[0], !negative)
+	}
+	return []summand{{node: node, negative: negative}}
+}
+
+// splitCoefficient returns number factor of product and the rest of product: "2*x*y" is 2 and "x*y",
+// "-x*y" is -1 and "x*y"
+func splitCoefficient(node *Node) (op.Number, *Node) {
+	if v, ok := constantValue(node); ok {
+		return v, nil
+	}
+	if node.Kind == OperandToken && node.Text == op.Mult.Symbol() {
+		list := factors(node)
+		if v, ok := constantValue(list[0]); ok {
+			return v, productNode(list[1:])
+		}
+		if list[0].isNeg() {
+			return "-1", productNode(append([]*Node{list[0].Args[0]}, list[1:]...))
+		}
+	}
+	return "1", node
+}
+
+// simplifyProduct multiplies numbers of product and sorts other factors: "y * 2 * x * 3" is "6*x*y",
+// minuses of factors are moved to number: "-x * -y" is "x*y", "y * -x" is "-x*y"
+func simplifyProduct(node *Node, p op.Precision) (*Node, bool) {
+	var (
+		coefficient = op.Number("1")
+		others      []*Node
+	)
+	for _, factor := range factors(node) {
+		v, ok := constantValue(factor)
+		if ok && !isFinite(factor) {
+			return nil, false
+		}
+		if !ok {
+			if !factor.isNeg() {
+				others = append(others, factor)
+				continue
+			}
+			v = "-1"
+			others = append(others, factor.Args[0])
+		}
+		var err error
+		if coefficient, err = p.ExecBinary(op.Mult, coefficient, v); err != nil {
+			return nil, false
+		}
+	}
+	// "0*(1/0)" isn't 0, it is kept to return error
+	if isTrue, err := coefficient.IsTrue(); err == nil && !isTrue {
+		if !allFinite(others) {
+			return nil, false
+		}
+		return numberNode(0), true
+	}
+	sort.SliceStable(others, func(i, j int) bool { return canonicalKey(others[i]) < canonicalKey(others[j]) })
+	value, ok := literalNode(coefficient)
+	if !ok {
+		return nil, false
+	}
+	switch {
+	case isNumber(value, -1) && len(others) > 0:
+		others[0] = operandNode(op.Neg, others[0])
+	case !isNumber(value, 1):
+		others = append([]*Node{value}, others...)
+	}
+	return productNode(others), true
+}
+
+// factors returns factors of chain of multiplications
+func factors(node *Node) []*Node {
+	if node.Kind == OperandToken && node.Text == op.Mult.Symbol() {
+		return append(factors(node.Args[0]), factors(node.Args[1])...)
+	}
+	return []*Node{node}
+}
+
+// productNode returns product of factors calculated from left to right
+func productNode(list []*Node) *Node {
+	if len(list) == 0 {
+		return numberNode(1)
+	}
+	res := list[0]
+	for _, factor := range list[1:] {
+		res = operandNode(op.Mult, res, factor)
+	}
+	return res
+}
+
+// canonicalKey returns text of tree which is used to sort terms and factors
+func canonicalKey(node *Node) string {
+	return FormatRPN(node.RPN())
+}
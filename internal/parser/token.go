@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+type TokenKind int
+
+const (
+	NumberToken TokenKind = iota
+	OperandToken
+	FunctionToken
+	// NameToken is a name of constant or variable which value is known only while calculating
+	NameToken
+)
+
+var tokenKindNames = map[TokenKind]string{
+	NumberToken:   "number",
+	OperandToken:  "operand",
+	FunctionToken: "function",
+	NameToken:     "name",
+}
+
+func (k TokenKind) String() string { return tokenKindNames[k] }
+
+func (k TokenKind) MarshalText() ([]byte, error) {
+	name, ok := tokenKindNames[k]
+	if !ok {
+		return nil, fmt.Errorf("unknown token kind %d", int(k))
+	}
+	return []byte(name), nil
+}
+
+func (k *TokenKind) UnmarshalText(text []byte) error {
+	for kind, name := range tokenKindNames {
+		if name == string(text) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown token kind '%s'", text)
+}
+
+// FunctionArgsSeparator separates function name and amount of its args in postfix expression: "max:3"
+const FunctionArgsSeparator = ":"
+
+// Token is one element of expression in reverse polish notation
+type Token struct {
+	Kind TokenKind `json:"kind"`
+	// Text is a number literal, operand symbol or function name
+	Text string `json:"text"`
+	// Args is amount of args of function
+	Args int `json:"args,omitempty"`
+	// Offset is a byte offset of number, name or function in infix expression, it isn't stored
+	Offset int `json:"-"`
+}
+
+// Number returns value of number token or built-in constant
+func (t Token) Number() (float64, error) {
+	switch t.Kind {
+	case NumberToken:
+		return ParseNumber(t.Text)
+	case NameToken:
+		v, ok := op.Constants[t.Text]
+		if !ok {
+			return 0, fmt.Errorf("unknown name '%s'", t.Text)
+		}
+		return v, nil
+	default:
+		return 0, fmt.Errorf("token '%s' is not a number", t.Text)
+	}
+}
+
+// Decimal returns value of number token or built-in constant as decimal string without rounding,
+// built-in constants are taken with float64 precision
+func (t Token) Decimal() (op.Number, error) {
+	if t.Kind == NumberToken {
+		digits, err := DecimalNumber(t.Text)
+		return op.Number(digits), err
+	}
+	if t.Kind == NameToken && t.Text == op.ImaginaryUnit {
+		return op.ComplexNumber(1i), nil
+	}
+	// unit is a value of one unit: "3 m" is "3 * (1 m)"
+	if t.Kind == NameToken && op.IsUnit(t.Text) {
+		return op.UnitNumber(t.Text), nil
+	}
+	v, err := t.Number()
+	if err != nil {
+		return "", err
+	}
+	return op.FloatNumber(v), nil
+}
+
+// Operand returns operand or function of token
+func (t Token) Operand() (op.Operand, error) {
+	if t.Kind == NumberToken || t.Kind == NameToken {
+		return nil, fmt.Errorf("token '%s' is not an operand", t.Text)
+	}
+	oper, ok := op.FindOperand(t.Text)
+	if !ok {
+		return nil, fmt.Errorf("unknown operand '%s'", t.Text)
+	}
+	return oper, nil
+}
+
+// ArgsCount returns amount of values token takes from stack while calculating RPN
+func (t Token) ArgsCount() int {
+	switch t.Kind {
+	case FunctionToken:
+		return t.Args
+	case OperandToken:
+		oper, _ := t.Operand()
+		if _, ok := oper.(op.UnaryOperand); ok {
+			return 1
+		}
+		return 2
+	default:
+		return 0
+	}
+}
+
+func (t Token) String() string {
+	if t.Kind == FunctionToken {
+		return fmt.Sprintf("%s%s%d", t.Text, FunctionArgsSeparator, t.Args)
+	}
+	return t.Text
+}
+
+// NumberTokens returns tokens of finite number in reverse polish notation,
+// negative number is written as number and unary minus
+func NumberTokens(v float64) []Token {
+	tokens := []Token{{Kind: NumberToken, Text: strconv.FormatFloat(math.Abs(v), 'g', -1, 64)}}
+	if v < 0 {
+		tokens = append(tokens, Token{Kind: OperandToken, Text: op.Neg.Symbol()})
+	}
+	return tokens
+}
+
+// FormatRPN returns tokens separated by spaces: "1 2 + 3 max:2 "
+func FormatRPN(tokens []Token) string {
+	var sb strings.Builder
+	for _, t := range tokens {
+		sb.WriteString(t.String())
+		sb.WriteByte(' ')
+	}
+	return sb.String()
+}
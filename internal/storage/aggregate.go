@@ -0,0 +1,83 @@
+package storage
+
+import (
+	"fmt"
+	"strconv"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+// reduceFanIn is the most amount of values which are calculated by one call of aggregate function
+// in one step of reduction
+const reduceFanIn = 8
+
+// aggregate calculates aggregate function of args. sum, min and max are calculated as a tree:
+// args are split into parts calculated by different computation servers at once, then the same
+// is done with results of parts. average is a sum divided by amount of args and standard deviation
+// is a square root of average of squared deviations, which are calculated at once too.
+// median isn't a reduction, so it is calculated by one computation server.
+// all operations are calculated with timeout of aggregate function
+func (s *storage) aggregate(addrCompServer string, dur int, fn op.AggregateOperand, args []op.Number, precision op.Precision) (op.Number, error) {
+	switch fn {
+	case op.Sum, op.Min, op.Max:
+		return s.reduce(addrCompServer, dur, fn, args, precision)
+	case op.Avg:
+		return s.average(addrCompServer, dur, args, precision)
+	case op.Stddev:
+		mean, err := s.average(addrCompServer, dur, args, precision)
+		if err != nil {
+			return "", err
+		}
+		squares, err := s.fanOut(addrCompServer, len(args), func(addr string, i int) (op.Number, error) {
+			deviation, err := calculateBinary(addr, dur, op.BinaryOperationInfo{A: args[i], B: mean, Op: op.Sub.Symbol(), Precision: precision})
+			if err != nil {
+				return "", err
+			}
+			return calculateBinary(addr, dur, op.BinaryOperationInfo{A: deviation, B: deviation, Op: op.Mult.Symbol(), Precision: precision})
+		})
+		if err != nil {
+			return "", err
+		}
+		variance, err := s.average(addrCompServer, dur, squares, precision)
+		if err != nil {
+			return "", err
+		}
+		info := op.FunctionOperationInfo{Args: []op.Number{variance}, Op: op.Sqrt.Symbol(), Precision: precision}
+		return calculateFunction(addrCompServer, dur, info)
+	default:
+		info := op.FunctionOperationInfo{Args: args, Op: fn.Symbol(), Precision: precision}
+		return calculateFunction(addrCompServer, dur, info)
+	}
+}
+
+// reduce calculates associative function fn of args by parts of reduceFanIn args until one value is left
+func (s *storage) reduce(addrCompServer string, dur int, fn op.FunctionOperand, args []op.Number, precision op.Precision) (op.Number, error) {
+	if len(args) == 0 {
+		return "", fmt.Errorf("%s has no args", fn.Symbol())
+	}
+	for {
+		parts := (len(args) + reduceFanIn - 1) / reduceFanIn
+		results, err := s.fanOut(addrCompServer, parts, func(addr string, i int) (op.Number, error) {
+			part := args[i*reduceFanIn : min((i+1)*reduceFanIn, len(args))]
+			info := op.FunctionOperationInfo{Args: part, Op: fn.Symbol(), Precision: precision}
+			return calculateFunction(addr, dur, info)
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(results) == 1 {
+			return results[0], nil
+		}
+		args = results
+	}
+}
+
+// average returns sum of args divided by amount of args
+func (s *storage) average(addrCompServer string, dur int, args []op.Number, precision op.Precision) (op.Number, error) {
+	total, err := s.reduce(addrCompServer, dur, op.Sum, args, precision)
+	if err != nil {
+		return "", err
+	}
+	info := op.BinaryOperationInfo{A: total, B: op.Number(strconv.Itoa(len(args))), Op: op.Div.Symbol(), Precision: precision}
+	return calculateBinary(addrCompServer, dur, info)
+}
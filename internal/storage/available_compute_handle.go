@@ -86,6 +86,20 @@ func (s *storage) handleHeartbeat(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *storage) getMostFreeComputationServer() (string, error) {
+	freeProcess := s.getFreeProcesses()
+	keys := make([]string, 0, len(freeProcess))
+	for key := range freeProcess {
+		keys = append(keys, key)
+	}
+	if len(keys) == 0 {
+		return "", fmt.Errorf("no availble computation server")
+	}
+	sort.Slice(keys, func(i, j int) bool { return freeProcess[keys[i]] > freeProcess[keys[j]] })
+	return keys[0], nil
+}
+
+// getFreeProcesses returns amount of free processes of working computation servers which have them
+func (s *storage) getFreeProcesses() map[string]int {
 	var (
 		wg          sync.WaitGroup
 		mu          sync.Mutex
@@ -119,18 +133,12 @@ func (s *storage) getMostFreeComputationServer() (string, error) {
 		}(addr)
 	}
 	wg.Wait()
-	keys := make([]string, 0, len(freeProcess))
 	for key := range freeProcess {
 		if freeProcess[key] == 0 {
-			continue
+			delete(freeProcess, key)
 		}
-		keys = append(keys, key)
 	}
-	if len(keys) == 0 {
-		return "", fmt.Errorf("no availble computation server")
-	}
-	sort.Slice(keys, func(i, j int) bool { return freeProcess[keys[i]] > freeProcess[keys[j]] })
-	return keys[0], nil
+	return freeProcess
 }
 
 func (s *storage) getWorkingComputationServers() []string {
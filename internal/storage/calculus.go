@@ -0,0 +1,327 @@
+package storage
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+	"github.com/XJIeI5/calculator/internal/parser"
+)
+
+const (
+	// integrateParts is amount of equal parts of interval of integral, they are integrated at once
+	// by free processes of all computation servers
+	integrateParts = 8
+	// integrateDepth is the most amount of halvings of part of interval
+	integrateDepth = 20
+	// maxSummationTerms is the most amount of terms of summation
+	maxSummationTerms = 100000
+)
+
+// calculateAt calculates tree with value x of variable, other bound variables keep their values
+func (s *storage) calculateAt(addrCompServer string, tree *parser.Node, e expr, variable string, x op.Number) (value, error) {
+	vars := map[string]op.Number{variable: x}
+	for name, v := range e.vars {
+		if name != variable {
+			vars[name] = v
+		}
+	}
+	e.vars = vars
+	return s.calculateInSync(addrCompServer, tree, e)
+}
+
+// boundArgs calculates args of node after its bound variable: start of solve, bounds of integral
+func (s *storage) boundArgs(addrCompServer string, node *parser.Node, e expr) ([]op.Number, error) {
+	args := make([]op.Number, len(node.Args)-2)
+	for i := range args {
+		v, err := s.calculateInSync(addrCompServer, node.Args[i+2], e)
+		if err != nil {
+			return nil, err
+		}
+		args[i] = v.number
+	}
+	return args, nil
+}
+
+// realValue returns real number as float64
+func realValue(n op.Number) (float64, error) {
+	r, err := n.Rat()
+	if err != nil {
+		return 0, fmt.Errorf("'%s' is not a real number", n)
+	}
+	v, _ := r.Float64()
+	if math.IsInf(v, 0) {
+		return 0, fmt.Errorf("'%s' is out of range", n)
+	}
+	return v, nil
+}
+
+// integrate returns integral of integrate(function, variable, a, b[, tolerance]) and its error estimate.
+// interval is split into integrateParts parts, every part is integrated by adaptive Simpson's method
+// by one computation server with its share of tolerance. integrals of parts are added up as sum
+// and their error estimates are added up too. all operations of adding are calculated with timeout of integrate.
+// estimate of integral of function with integral inside isn't known
+func (s *storage) integrate(addrCompServer string, node *parser.Node, e expr) (op.Number, op.Number, error) {
+	args, err := s.boundArgs(addrCompServer, node, e)
+	if err != nil {
+		return "", "", err
+	}
+	bounds := make([]float64, 2)
+	for i := range bounds {
+		if bounds[i], err = realValue(args[i]); err != nil {
+			return "", "", fmt.Errorf("bound of integral: %w", err)
+		}
+	}
+	tolerance := op.DefaultIntegrateTolerance
+	if len(args) > 2 {
+		if tolerance, err = realValue(args[2]); err != nil || tolerance <= 0 {
+			return "", "", fmt.Errorf("tolerance of integrate must be a positive number, got '%s'", args[2])
+		}
+	}
+	duration, err := getOperandTime(s.db, op.Integrate.Symbol(), e.userId)
+	if err != nil {
+		return "", "", fmt.Errorf("no timeout for '%s'", op.Integrate.Symbol())
+	}
+
+	a, b := bounds[0], bounds[1]
+	width := (b - a) / integrateParts
+	estimates := make([]float64, integrateParts)
+	parts, err := s.fanOut(addrCompServer, integrateParts, func(addr string, i int) (op.Number, error) {
+		lo, hi := a+float64(i)*width, a+float64(i+1)*width
+		if i == integrateParts-1 {
+			hi = b
+		}
+		q := quadrature{s: s, addrCompServer: addr, f: node.Args[0], variable: node.Args[1].Text, e: e}
+		integral, estimate, err := q.integrate(lo, hi, tolerance/integrateParts)
+		estimates[i] = estimate
+		return op.FloatNumber(integral), err
+	})
+	if err != nil {
+		return "", "", err
+	}
+	integral, err := s.reduce(addrCompServer, int(duration.Milliseconds()), op.Sum, parts, e.precision)
+	if err != nil {
+		return "", "", err
+	}
+	if hasIntegral(node.Args[0]) {
+		return integral, estimateUnavailable, nil
+	}
+	var estimate float64
+	for _, v := range estimates {
+		estimate += v
+	}
+	return integral, op.FloatNumber(estimate), nil
+}
+
+// hasIntegral returns true if integrate is called in tree
+func hasIntegral(node *parser.Node) bool {
+	if node.Kind == parser.FunctionToken && node.Text == op.Integrate.Symbol() {
+		return true
+	}
+	for _, arg := range node.Args {
+		if hasIntegral(arg) {
+			return true
+		}
+	}
+	return false
+}
+
+// quadrature integrates function by adaptive Simpson's method, function is calculated by one computation server
+type quadrature struct {
+	s              *storage
+	addrCompServer string
+	f              *parser.Node
+	variable       string
+	e              expr
+}
+
+// at returns value of function at x
+func (q quadrature) at(x float64) (float64, error) {
+	v, err := q.s.calculateAt(q.addrCompServer, q.f, q.e, q.variable, op.FloatNumber(x))
+	if err != nil {
+		return 0, err
+	}
+	res, err := realValue(v.number)
+	if err != nil {
+		return 0, fmt.Errorf("integral is defined only for real functions, function is %s at %g", v.number, x)
+	}
+	return res, nil
+}
+
+// integrate returns integral from a to b and its error estimate
+func (q quadrature) integrate(a, b, tolerance float64) (float64, float64, error) {
+	if a == b {
+		return 0, 0, nil
+	}
+	m := (a + b) / 2
+	values := make([]float64, 3)
+	for i, x := range []float64{a, m, b} {
+		v, err := q.at(x)
+		if err != nil {
+			return 0, 0, err
+		}
+		values[i] = v
+	}
+	whole := simpson(a, b, values[0], values[1], values[2])
+	return q.adapt(a, b, values[0], values[1], values[2], whole, tolerance, integrateDepth)
+}
+
+// adapt halves interval until Simpson's rule of halves differs from the rule of the whole interval
+// by less than 15 tolerances, the difference divided by 15 is the error estimate of the halves.
+// interval which isn't integrated with tolerance after depth halvings is returned with its estimate
+func (q quadrature) adapt(a, b, fa, fm, fb, whole, tolerance float64, depth int) (float64, float64, error) {
+	m := (a + b) / 2
+	flm, err := q.at((a + m) / 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	frm, err := q.at((m + b) / 2)
+	if err != nil {
+		return 0, 0, err
+	}
+	left, right := simpson(a, m, fa, flm, fm), simpson(m, b, fm, frm, fb)
+	delta := left + right - whole
+	if depth == 0 || math.Abs(delta) <= 15*tolerance {
+		return left + right + delta/15, math.Abs(delta) / 15, nil
+	}
+	leftIntegral, leftEstimate, err := q.adapt(a, m, fa, flm, fm, left, tolerance/2, depth-1)
+	if err != nil {
+		return 0, 0, err
+	}
+	rightIntegral, rightEstimate, err := q.adapt(m, b, fm, frm, fb, right, tolerance/2, depth-1)
+	if err != nil {
+		return 0, 0, err
+	}
+	return leftIntegral + rightIntegral, leftEstimate + rightEstimate, nil
+}
+
+// simpson returns integral from a to b by Simpson's rule with values of function at a, middle and b
+func simpson(a, b, fa, fm, fb float64) float64 {
+	return (b - a) / 6 * (fa + 4*fm + fb)
+}
+
+// summation returns sum(term, index, from, to): sum of terms for integer indexes from "from" to "to".
+// indexes are split into parts of reduceFanIn terms, every part is calculated and added up by one
+// computation server, then sums of parts are added up as aggregate sum. sum of empty range is 0.
+// error estimate of summation is sum of estimates of terms with integrals
+func (s *storage) summation(addrCompServer string, node *parser.Node, e expr) (op.Number, op.Number, error) {
+	args, err := s.boundArgs(addrCompServer, node, e)
+	if err != nil {
+		return "", "", err
+	}
+	bounds := make([]*big.Int, 2)
+	for i := range bounds {
+		r, err := args[i].Rat()
+		if err != nil || !r.IsInt() {
+			return "", "", fmt.Errorf("bounds of summation must be integers, got '%s'", args[i])
+		}
+		bounds[i] = r.Num()
+	}
+	// amount of terms is counted in big.Int, because "to - from" of int64 bounds can overflow
+	from := bounds[0]
+	count := new(big.Int).Sub(bounds[1], from)
+	count.Add(count, big.NewInt(1))
+	if count.Sign() <= 0 {
+		return "0", "", nil
+	}
+	if count.Cmp(big.NewInt(maxSummationTerms)) > 0 {
+		return "", "", fmt.Errorf("summation has %s terms, the most is %d", count, maxSummationTerms)
+	}
+	duration, err := getOperandTime(s.db, op.Sum.Symbol(), e.userId)
+	if err != nil {
+		return "", "", fmt.Errorf("no timeout for '%s'", op.Sum.Symbol())
+	}
+	dur := int(duration.Milliseconds())
+
+	n := int(count.Int64())
+	estimates := make([]op.Number, n)
+	parts, err := s.fanOut(addrCompServer, (n+reduceFanIn-1)/reduceFanIn, func(addr string, i int) (op.Number, error) {
+		terms := make([]op.Number, 0, reduceFanIn)
+		for j := i * reduceFanIn; j < min((i+1)*reduceFanIn, n); j++ {
+			index := op.Number(new(big.Int).Add(from, big.NewInt(int64(j))).String())
+			term, err := s.calculateAt(addr, node.Args[0], e, node.Args[1].Text, index)
+			if err != nil {
+				return "", err
+			}
+			terms = append(terms, term.number)
+			estimates[j] = term.estimate
+		}
+		info := op.FunctionOperationInfo{Args: terms, Op: op.Sum.Symbol(), Precision: e.precision}
+		return calculateFunction(addr, dur, info)
+	})
+	if err != nil {
+		return "", "", err
+	}
+	sum, err := s.reduce(addrCompServer, dur, op.Sum, parts, e.precision)
+	return sum, addEstimates(estimates), err
+}
+
+// estimateUnavailable is error estimate of value which is calculated from integral, but its error isn't known:
+// "sin(integrate(x, x, 0, 1))", "integrate(integrate(x*y, y, 0, 1), x, 0, 1)"
+const estimateUnavailable op.Number = "unavailable"
+
+// estimateValue returns error estimate as float64, it is false if estimate isn't known
+func estimateValue(estimate op.Number) (float64, bool) {
+	r, err := estimate.Rat()
+	if err != nil {
+		return 0, false
+	}
+	res, _ := r.Float64()
+	return res, true
+}
+
+// addEstimates returns error estimate of sum of values with estimates, values without estimates are exact
+func addEstimates(estimates []op.Number) op.Number {
+	var (
+		res   float64
+		found bool
+	)
+	for _, estimate := range estimates {
+		if estimate == "" {
+			continue
+		}
+		v, ok := estimateValue(estimate)
+		if !ok {
+			return estimateUnavailable
+		}
+		res, found = res+v, true
+	}
+	if !found {
+		return ""
+	}
+	return op.FloatNumber(res)
+}
+
+// propagateEstimate returns error estimate of result of operand by estimates of its args. it is known
+// only for linear operations: estimates are added up by addition, subtraction and sum,
+// multiplication and division by exact number scales estimate: "2*integrate(x, x, 0, 1) + 1"
+func propagateEstimate(operand op.Operand, args, estimates []op.Number) op.Number {
+	estimated := -1
+	for i, estimate := range estimates {
+		if estimate == "" {
+			continue
+		}
+		if estimated >= 0 && operand != op.Add && operand != op.Sub && operand != op.Sum {
+			return estimateUnavailable
+		}
+		estimated = i
+	}
+	switch {
+	case estimated < 0:
+		return ""
+	case operand == op.Add || operand == op.Sub || operand == op.Sum || operand == op.Neg || operand == op.Pos:
+		return addEstimates(estimates)
+	case operand == op.Mult || (operand == op.Div && estimated == 0):
+		estimate, ok := estimateValue(estimates[estimated])
+		factor, err := realValue(args[1-estimated])
+		if !ok || err != nil {
+			return estimateUnavailable
+		}
+		if operand == op.Div {
+			return op.FloatNumber(estimate / math.Abs(factor))
+		}
+		return op.FloatNumber(estimate * math.Abs(factor))
+	}
+	return estimateUnavailable
+}
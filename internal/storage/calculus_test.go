@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"database/sql"
+	"testing"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+	"github.com/XJIeI5/calculator/internal/parser"
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// newTestStorage returns storage with one computation server and user 1, operations have no timeouts
+func newTestStorage(t *testing.T, addrCompServer string) *storage {
+	db, err := sql.Open("sqlite3", ":memory:")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	// every connection to memory database has its own database
+	db.SetMaxOpenConns(1)
+	for _, q := range []string{
+		`CREATE TABLE timeouts(id INTEGER PRIMARY KEY AUTOINCREMENT, type TEXT, value INTEGER NOT NULL, userId INTEGER NOT NULL)`,
+		`CREATE TABLE constants(id INTEGER PRIMARY KEY AUTOINCREMENT, name TEXT, value REAL NOT NULL, userId INTEGER NOT NULL)`,
+	} {
+		if _, err := db.Exec(q); err != nil {
+			t.Fatalf("error got '%s'", err)
+		}
+	}
+	if err := storeMissingTimeouts(db, 1); err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	if _, err := db.Exec(`UPDATE timeouts SET value = 0`); err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	return &storage{db: db, computationServers: map[string]int64{addrCompServer: 0}}
+}
+
+// calculate resolves names of expression, simplifies it and calculates it like added expression
+func calculate(t *testing.T, s *storage, addrCompServer, infixExpr string) (value, error) {
+	tree, err := parser.Parse(infixExpr)
+	if err != nil {
+		t.Fatalf("'%s': error got '%s'", infixExpr, err)
+	}
+	tokens, err := resolveNames(s.db, tree.RPN(), 1, nil, infixExpr)
+	if err != nil {
+		return value{}, err
+	}
+	if tree, err = parser.FromRPN(tokens); err != nil {
+		t.Fatalf("'%s': error got '%s'", infixExpr, err)
+	}
+	return s.calculateInSync(addrCompServer, parser.Simplify(tree, op.Precision{}), expr{userId: 1})
+}
+
+func TestSummation(t *testing.T) {
+	server := newComputeServer()
+	defer server.Close()
+	s := newTestStorage(t, server.URL)
+
+	for expr, expected := range map[string]string{
+		"sum(k^2, k, 1, 100)":    "338350",
+		"sum(k, k, 5, 4)":        "0",
+		"sum(k, 2, 3, 4)":        "",
+		"sum(1, 2, 3, 4)":        "10",
+		"sum(k, k, -9e18, 9e18)": "",
+	} {
+		res, err := calculate(t, s, server.URL, expr)
+		if expected == "" {
+			if err == nil {
+				t.Errorf("'%s': expected error, got '%s'", expr, res.number)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("'%s': error got '%s'", expr, err)
+			continue
+		}
+		if string(res.number) != expected {
+			t.Errorf("'%s': value is not '%s', got '%s'", expr, expected, res.number)
+		}
+	}
+}
+
+func TestErrorEstimate(t *testing.T) {
+	server := newComputeServer()
+	defer server.Close()
+	s := newTestStorage(t, server.URL)
+
+	integral, err := calculate(t, s, server.URL, "integrate(x^2, x, 0, 1)")
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	estimate, ok := estimateValue(integral.estimate)
+	if !ok {
+		t.Fatalf("estimate of integral is not a number, got '%s'", integral.estimate)
+	}
+	for expr, expected := range map[string]op.Number{
+		"2*integrate(x^2, x, 0, 1) + 1":               op.FloatNumber(2 * estimate),
+		"integrate(x^2, x, 0, 1)/4 - 1":               op.FloatNumber(estimate / 4),
+		"-integrate(x^2, x, 0, 1)":                    op.FloatNumber(estimate),
+		"sum(integrate(x^2, x, 0, 1), 1)":             op.FloatNumber(estimate),
+		"sum(k*integrate(x^2, x, 0, 1), k, 1, 3)":     op.FloatNumber(estimate + 2*estimate + 3*estimate),
+		"sin(integrate(x^2, x, 0, 1))":                estimateUnavailable,
+		"integrate(x^2, x, 0, 1)^2":                   estimateUnavailable,
+		"integrate(integrate(x*y, y, 0, 1), x, 0, 1)": estimateUnavailable,
+		"integrate(x^2, x, 0, 1) > 0":                 "",
+		"sin(1) + 2":                                  "",
+	} {
+		res, err := calculate(t, s, server.URL, expr)
+		if err != nil {
+			t.Errorf("'%s': error got '%s'", expr, err)
+			continue
+		}
+		if res.estimate != expected {
+			t.Errorf("'%s': estimate is not '%s', got '%s'", expr, expected, res.estimate)
+		}
+	}
+}
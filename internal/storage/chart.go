@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"fmt"
+	"html"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// size of svg chart and space around plot for labels in pixels
+const (
+	chartWidth  = 640
+	chartHeight = 400
+	chartMargin = 48
+)
+
+// renderChart returns svg line chart of points titled by expression. points without real value
+// break the line: "1/x" is drawn as two lines around 0
+func renderChart(title string, points []tabulatePoint) []byte {
+	var (
+		sb         strings.Builder
+		lines      [][2]float64
+		xMin, xMax = math.Inf(1), math.Inf(-1)
+		yMin, yMax = math.Inf(1), math.Inf(-1)
+	)
+	for _, p := range points {
+		xMin, xMax = math.Min(xMin, p.X), math.Max(xMax, p.X)
+		if y, ok := chartValue(p.Y); ok {
+			yMin, yMax = math.Min(yMin, y), math.Max(yMax, y)
+		}
+	}
+	if len(points) == 0 {
+		xMin, xMax = 0, 1
+	}
+	if math.IsInf(yMin, 0) {
+		yMin, yMax = 0, 1
+	}
+	// constant function is drawn in the middle
+	if xMin == xMax {
+		xMin, xMax = xMin-1, xMax+1
+	}
+	if yMin == yMax {
+		yMin, yMax = yMin-1, yMax+1
+	}
+	plotWidth, plotHeight := float64(chartWidth-2*chartMargin), float64(chartHeight-2*chartMargin)
+	toX := func(x float64) float64 { return chartMargin + (x-xMin)/(xMax-xMin)*plotWidth }
+	toY := func(y float64) float64 { return chartMargin + (yMax-y)/(yMax-yMin)*plotHeight }
+
+	fmt.Fprintf(&sb, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`+"\n",
+		chartWidth, chartHeight, chartWidth, chartHeight)
+	fmt.Fprintf(&sb, `<rect x="%d" y="%d" width="%s" height="%s" fill="white" stroke="#cccccc"/>`+"\n",
+		chartMargin, chartMargin, pixels(plotWidth), pixels(plotHeight))
+	// axes are drawn if they are inside of plot
+	if xMin <= 0 && 0 <= xMax {
+		fmt.Fprintf(&sb, `<line x1="%s" y1="%d" x2="%s" y2="%d" stroke="#999999"/>`+"\n",
+			pixels(toX(0)), chartMargin, pixels(toX(0)), chartHeight-chartMargin)
+	}
+	if yMin <= 0 && 0 <= yMax {
+		fmt.Fprintf(&sb, `<line x1="%d" y1="%s" x2="%d" y2="%s" stroke="#999999"/>`+"\n",
+			chartMargin, pixels(toY(0)), chartWidth-chartMargin, pixels(toY(0)))
+	}
+
+	// line of one point isn't visible, so it is drawn as a dot
+	flush := func() {
+		if len(lines) == 1 {
+			fmt.Fprintf(&sb, `<circle cx="%s" cy="%s" r="2" fill="#1f77b4"/>`+"\n", pixels(lines[0][0]), pixels(lines[0][1]))
+		}
+		if len(lines) > 1 {
+			coords := make([]string, len(lines))
+			for i, p := range lines {
+				coords[i] = pixels(p[0]) + "," + pixels(p[1])
+			}
+			fmt.Fprintf(&sb, `<polyline fill="none" stroke="#1f77b4" stroke-width="1.5" points="%s"/>`+"\n", strings.Join(coords, " "))
+		}
+		lines = lines[:0]
+	}
+	for _, p := range points {
+		y, ok := chartValue(p.Y)
+		if !ok {
+			flush()
+			continue
+		}
+		lines = append(lines, [2]float64{toX(p.X), toY(y)})
+	}
+	flush()
+
+	label := func(x, y float64, anchor, text string) {
+		fmt.Fprintf(&sb, `<text x="%s" y="%s" font-family="sans-serif" font-size="12" text-anchor="%s">%s</text>`+"\n",
+			pixels(x), pixels(y), anchor, html.EscapeString(text))
+	}
+	label(chartWidth/2, chartMargin/2, "middle", title)
+	label(chartMargin, chartHeight-chartMargin+16, "start", strconv.FormatFloat(xMin, 'g', 6, 64))
+	label(chartWidth-chartMargin, chartHeight-chartMargin+16, "end", strconv.FormatFloat(xMax, 'g', 6, 64))
+	label(chartMargin-4, chartMargin+4, "end", strconv.FormatFloat(yMax, 'g', 6, 64))
+	label(chartMargin-4, chartHeight-chartMargin, "end", strconv.FormatFloat(yMin, 'g', 6, 64))
+	sb.WriteString("</svg>\n")
+	return []byte(sb.String())
+}
+
+// chartValue returns value of point as float64, fractions of rational precision are values too
+func chartValue(y string) (float64, bool) {
+	r, ok := new(big.Rat).SetString(y)
+	if !ok {
+		return 0, false
+	}
+	v, _ := r.Float64()
+	return v, !math.IsInf(v, 0)
+}
+
+// pixels returns coordinate rounded to hundredths of pixel
+func pixels(v float64) string {
+	return strconv.FormatFloat(v, 'f', 2, 64)
+}
@@ -0,0 +1,228 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+	"github.com/XJIeI5/calculator/internal/parser"
+)
+
+func (s *storage) handleSetConstants(w http.ResponseWriter, r *http.Request) {
+	if t := r.Header.Get("Content-Type"); t != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	bearerToken := r.Header.Get("Authorization")
+	if bearerToken == "" {
+		http.Error(w, `no header "Authorization"`, http.StatusBadRequest)
+		return
+	}
+	userId, err := getUserId(bearerToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	constants := struct {
+		Value map[string]float64 `json:"constants"`
+	}{}
+	err = json.NewDecoder(r.Body).Decode(&constants)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	for name := range constants.Value {
+		if err := checkName(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for name, value := range constants.Value {
+		if err := storeConstant(s.db, name, value, userId); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func (s *storage) handleGetConstants(w http.ResponseWriter, r *http.Request) {
+	bearerToken := r.Header.Get("Authorization")
+	if bearerToken == "" {
+		http.Error(w, `no header "Authorization"`, http.StatusBadRequest)
+		return
+	}
+	userId, err := getUserId(bearerToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	constants, err := getConstants(s.db, userId)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	data, err := json.Marshal(constants)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write(data)
+}
+
+func (s *storage) handleDeleteConstants(w http.ResponseWriter, r *http.Request) {
+	if t := r.Header.Get("Content-Type"); t != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	bearerToken := r.Header.Get("Authorization")
+	if bearerToken == "" {
+		http.Error(w, `no header "Authorization"`, http.StatusBadRequest)
+		return
+	}
+	userId, err := getUserId(bearerToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	names := struct {
+		Value []string `json:"names"`
+	}{}
+	err = json.NewDecoder(r.Body).Decode(&names)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, name := range names.Value {
+		if err := deleteConstant(s.db, name, userId); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// checkName returns error if name can't be used as user constant or variable
+func checkName(name string) error {
+	if name == "" || parser.GetStringName(name) != name {
+		return fmt.Errorf("'%s' is not a valid name", name)
+	}
+	if op.IsConstant(name) {
+		return fmt.Errorf("'%s' is a built-in constant", name)
+	}
+	if _, ok := op.FindFunction(name); ok {
+		return fmt.Errorf("'%s' is a function", name)
+	}
+	if op.HaveOperand(name) {
+		return fmt.Errorf("'%s' is an operand", name)
+	}
+	return nil
+}
+
+// resolveNames replaces names of variables and user constants in expression by their values,
+// variables are bound to expression and hide user constants with the same name.
+// built-in constants and units are left as names, they are calculated by parser.Token.Decimal.
+// variables of solve, integrate and summation are left as names in their first arg: "solve(x^2 = a, x, 1)".
+// infixExpr is used to show position of unknown name in error
+func resolveNames(db *sql.DB, tokens []parser.Token, userId int, vars map[string]float64, infixExpr string) ([]parser.Token, error) {
+	return resolveFreeNames(db, tokens, userId, vars, nil, infixExpr)
+}
+
+// resolveFreeNames is resolveNames which leaves bound names as they are: argument of tabulated function
+func resolveFreeNames(db *sql.DB, tokens []parser.Token, userId int, vars map[string]float64, bound map[string]bool, infixExpr string) ([]parser.Token, error) {
+	values, err := getConstants(db, userId)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range vars {
+		values[name] = value
+	}
+	tree, err := parser.FromRPN(tokens)
+	if err != nil {
+		return nil, err
+	}
+	res, err := resolveNode(tree, values, bound, infixExpr)
+	if err != nil {
+		return nil, err
+	}
+	return res.RPN(), nil
+}
+
+// resolveNode returns tree with resolved names, bound are variables of solve calls node is in
+func resolveNode(node *parser.Node, values map[string]float64, bound map[string]bool, infixExpr string) (*parser.Node, error) {
+	if node.Kind == parser.NameToken {
+		if bound[node.Text] || op.IsConstant(node.Text) {
+			return node, nil
+		}
+		v, ok := values[node.Text]
+		// units are hidden by variables and user constants with the same name
+		if !ok && op.IsUnit(node.Text) {
+			return node, nil
+		}
+		if !ok {
+			return nil, parser.UnknownNameError(infixExpr, node.Token)
+		}
+		return parser.FromRPN(parser.NumberTokens(v))
+	}
+	operand, _ := node.Operand()
+	if operand == op.Equation {
+		return nil, errors.New("equation can be written only as the first arg of solve")
+	}
+	variable, ok := node.BoundVariable()
+	if !ok {
+		return resolveArgs(node, values, bound, infixExpr)
+	}
+	if variable.Kind != parser.NameToken {
+		return nil, fmt.Errorf("the second arg of %s must be a variable, got '%s'", node.Text, variable.Infix())
+	}
+	if err := checkName(variable.Text); err != nil {
+		return nil, err
+	}
+	inner := map[string]bool{variable.Text: true}
+	for name := range bound {
+		inner[name] = true
+	}
+	res := &parser.Node{Token: node.Token, Args: make([]*parser.Node, len(node.Args))}
+	for i, arg := range node.Args {
+		var err error
+		switch argOperand, _ := arg.Operand(); {
+		case i == 1:
+			res.Args[i] = arg
+		case i == 0 && operand == op.Solve && argOperand == op.Equation:
+			res.Args[i], err = resolveArgs(arg, values, inner, infixExpr)
+		case i == 0:
+			res.Args[i], err = resolveNode(arg, values, inner, infixExpr)
+		default:
+			res.Args[i], err = resolveNode(arg, values, bound, infixExpr)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return res, nil
+}
+
+// resolveArgs returns node with resolved args
+func resolveArgs(node *parser.Node, values map[string]float64, bound map[string]bool, infixExpr string) (*parser.Node, error) {
+	res := &parser.Node{Token: node.Token, Args: make([]*parser.Node, len(node.Args))}
+	for i, arg := range node.Args {
+		resolved, err := resolveNode(arg, values, bound, infixExpr)
+		if err != nil {
+			return nil, err
+		}
+		res.Args[i] = resolved
+	}
+	return res, nil
+}
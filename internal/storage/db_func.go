@@ -2,9 +2,11 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
 	"strconv"
 	"time"
 
+	op "github.com/XJIeI5/calculator/internal/operation"
 	"github.com/dgrijalva/jwt-go"
 )
 
@@ -33,9 +35,9 @@ func getUserId(bearerToken string) (int, error) {
 	return id, nil
 }
 
-func storeExpressionState(db *sql.DB, status state, result interface{}, bearerToken string, _expr postfixExpr) (int64, error) {
+func storeExpressionState(db *sql.DB, status state, result interface{}, bearerToken string, _expr rpnExpr, hash exprHash, precision op.Precision, evaluatedAt time.Time) (int64, error) {
 	var q string = `
-	INSERT INTO expressions (status, result, userId, hash, postfixExpression) VALUES ($1, $2, $3, $4, $5)
+	INSERT INTO expressions (status, result, userId, hash, postfixExpression, precision, evaluatedAt) VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
 
 	id, err := getUserId(bearerToken)
@@ -43,7 +45,7 @@ func storeExpressionState(db *sql.DB, status state, result interface{}, bearerTo
 		panic(err)
 	}
 
-	res, err := db.Exec(q, status, result, id, getHash(string(_expr)), _expr)
+	res, err := db.Exec(q, status, result, id, hash, _expr, precision.String(), evaluatedAt.Format(time.RFC3339))
 	if err != nil {
 		panic(err)
 	}
@@ -60,6 +62,30 @@ func updateExpressionState(db *sql.DB, status state, result interface{}, hash ex
 	}
 }
 
+// updateExpressionProgress stores state of calculation which takes many steps, it is returned with result
+func updateExpressionProgress(db *sql.DB, progress interface{}, hash exprHash) error {
+	var q string = `
+	UPDATE expressions SET progress = $1 WHERE hash = $2
+	`
+
+	data, err := json.Marshal(progress)
+	if err != nil {
+		return err
+	}
+	_, err = db.Exec(q, string(data), hash)
+	return err
+}
+
+// updateExpressionEstimate stores error estimate of result, it is returned with result
+func updateExpressionEstimate(db *sql.DB, estimate string, hash exprHash) error {
+	var q string = `
+	UPDATE expressions SET errorEstimate = $1 WHERE hash = $2
+	`
+
+	_, err := db.Exec(q, estimate, hash)
+	return err
+}
+
 func checkExpressionExists(db *sql.DB, hash exprHash, bearerToken string) (int64, error) {
 	var q string = `
 	SELECT id FROM expressions WHERE hash = $1 AND userId = $2
@@ -77,14 +103,82 @@ func checkExpressionExists(db *sql.DB, hash exprHash, bearerToken string) (int64
 
 func getExpressionState(db *sql.DB, id int) (expressionState, error) {
 	var q string = `
-	SELECT status, result FROM expressions WHERE id = $1`
+	SELECT status, result, precision, evaluatedAt, progress, errorEstimate FROM expressions WHERE id = $1`
 	var (
-		st     state
-		result string
+		st          state
+		result      sql.NullString
+		name        sql.NullString
+		evaluatedAt sql.NullString
+		progress    sql.NullString
+		estimate    sql.NullString
 	)
-	if err := db.QueryRow(q, id).Scan(&st, &result); err != nil {
+	if err := db.QueryRow(q, id).Scan(&st, &result, &name, &evaluatedAt, &progress, &estimate); err != nil {
 		return expressionState{}, err
 	}
+	// result of expression in progress isn't stored yet
+	res, err := expressionResult(st, result.String, name)
+	res.EvaluatedAt = evaluatedAt.String
+	res.ErrorEstimate = estimate.String
+	if progress.Valid {
+		res.Progress = json.RawMessage(progress.String)
+	}
+	return res, err
+}
+
+// expressionResult returns result stored in expressions table with its parts
+func expressionResult(st state, result string, name sql.NullString) (expressionState, error) {
+	// booleans are stored as "true" and "false"
+	switch result {
+	case "true":
+		return expressionState{State: st, Result: true}, nil
+	case "false":
+		return expressionState{State: st, Result: false}, nil
+	}
+	// elements of rational matrices are stored as fractions and returned as decimals
+	if st == ok && op.Number(result).IsMatrix() {
+		var precision op.Precision
+		res := expressionState{State: st, Result: result}
+		if name.Valid && precision.UnmarshalText([]byte(name.String)) == nil && precision.Kind == op.Rational {
+			decimal, err := precision.Decimal(op.Number(result))
+			if err != nil {
+				return expressionState{}, err
+			}
+			res.Result, res.Fraction = decimal, result
+		}
+		m, err := op.Number(res.Result.(string)).Matrix()
+		res.Matrix = m
+		return res, err
+	}
+	if st == ok && op.Number(result).IsQuantity() {
+		q, err := op.Number(result).Quantity()
+		if err != nil {
+			return expressionState{}, err
+		}
+		return expressionState{State: st, Result: result, Quantity: &quantityResult{Value: q.Value, Unit: q.Unit.String()}}, nil
+	}
+	if st == ok && op.Number(result).IsInterval() {
+		iv, err := op.Number(result).Interval()
+		if err != nil {
+			return expressionState{}, err
+		}
+		return expressionState{State: st, Result: result, Interval: &intervalResult{Lo: iv.Lo, Hi: iv.Hi}}, nil
+	}
+	if st == ok && op.Number(result).IsComplex() {
+		c, err := op.Number(result).Complex()
+		if err != nil {
+			return expressionState{}, err
+		}
+		return expressionState{State: st, Result: result, Complex: &complexResult{Real: real(c), Imag: imag(c)}}, nil
+	}
+	// results of rational expressions are stored as fractions and returned with decimal approximation
+	var precision op.Precision
+	if st == ok && name.Valid && precision.UnmarshalText([]byte(name.String)) == nil && precision.Kind == op.Rational {
+		decimal, err := precision.Decimal(op.Number(result))
+		if err != nil {
+			return expressionState{}, err
+		}
+		return expressionState{State: st, Result: decimal, Fraction: result}, nil
+	}
 	return expressionState{State: st, Result: result}, nil
 }
 
@@ -141,6 +235,101 @@ func storeTimeout(db *sql.DB, operand string, value int, userId int) error {
 	return nil
 }
 
+// storeMissingTimeouts sets default timeout for every math operand user doesn't have timeout for
+func storeMissingTimeouts(db *sql.DB, userId int) error {
+	for _, operand := range op.Operands {
+		if _, ok := operand.(op.MathOperand); !ok {
+			continue
+		}
+		_, err := getOperandTime(db, operand.Symbol(), userId)
+		if err == nil {
+			continue
+		}
+		if err != sql.ErrNoRows {
+			return err
+		}
+		if err := storeTimeout(db, operand.Symbol(), defaultOperandTimeout, userId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getUserIds(db *sql.DB) ([]int, error) {
+	var q string = `
+	SELECT id FROM users
+	`
+	rows, err := db.Query(q)
+	if err != nil {
+		return []int{}, err
+	}
+	defer rows.Close()
+	ids := make([]int, 0)
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return []int{}, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
+
+func getConstants(db *sql.DB, userId int) (map[string]float64, error) {
+	var q string = `
+	SELECT name, value FROM constants WHERE userId = $1
+	`
+	res := make(map[string]float64)
+	rows, err := db.Query(q, userId)
+	if err != nil {
+		return res, err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			name  string
+			value float64
+		)
+		if err := rows.Scan(&name, &value); err != nil {
+			return res, err
+		}
+		res[name] = value
+	}
+	return res, nil
+}
+
+func storeConstant(db *sql.DB, name string, value float64, userId int) error {
+	var (
+		q string = `
+		SELECT id FROM constants WHERE name = $1 AND userId = $2
+		`
+		id int
+	)
+
+	if err := db.QueryRow(q, name, userId).Scan(&id); err != nil {
+		q = `
+		INSERT INTO constants (name, value, userId) VALUES ($1, $2, $3)
+		`
+		if _, err := db.Exec(q, name, value, userId); err != nil {
+			return err
+		}
+	} else {
+		q = `
+		UPDATE constants SET value = $1 WHERE id = $2`
+		if _, err := db.Exec(q, value, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func deleteConstant(db *sql.DB, name string, userId int) error {
+	var q string = `
+	DELETE FROM constants WHERE name = $1 AND userId = $2`
+	_, err := db.Exec(q, name, userId)
+	return err
+}
+
 func getComputes(db *sql.DB) (map[string]int64, error) {
 	var q string = `
 	SELECT address, lastPing FROM computes
@@ -188,22 +377,53 @@ func pingCompute(db *sql.DB, addr string, lastPing int64) error {
 
 func getInProcessExpressions(db *sql.DB) ([]expr, error) {
 	var q string = `
-	SELECT postfixExpression, userId FROM expressions WHERE status = $1
+	SELECT postfixExpression, hash, userId, precision, evaluatedAt FROM expressions WHERE status = $1
 	`
 	rows, err := db.Query(q, in_progress)
 	if err != nil {
 		return []expr{}, err
 	}
 	expressions := make([]expr, 0)
+	outdated := make([]exprHash, 0)
 	for rows.Next() {
 		var (
-			_expr  string
-			userId int
+			_expr     rpnExpr
+			data      string
+			hash      exprHash
+			userId    int
+			name      sql.NullString
+			precision op.Precision
+			at        sql.NullString
 		)
-		if err := rows.Scan(&_expr, &userId); err != nil {
+		if err := rows.Scan(&data, &hash, &userId, &name, &at); err != nil {
+			rows.Close()
 			return []expr{}, err
 		}
-		expressions = append(expressions, expr{postfixExpr: postfixExpr(_expr), userId: userId})
+		// expressions were stored as postfix string before
+		if err := _expr.Scan(data); err != nil {
+			outdated = append(outdated, hash)
+			continue
+		}
+		// expressions stored before precision was added are calculated with float64
+		if name.Valid {
+			if err := precision.UnmarshalText([]byte(name.String)); err != nil {
+				outdated = append(outdated, hash)
+				continue
+			}
+		}
+		// expressions stored before evaluation time was added are calculated with the current time
+		evaluatedAt := time.Now().UTC().Truncate(time.Second)
+		if at.Valid {
+			if evaluatedAt, err = time.Parse(time.RFC3339, at.String); err != nil {
+				outdated = append(outdated, hash)
+				continue
+			}
+		}
+		expressions = append(expressions, expr{rpnExpr: _expr, hash: hash, userId: userId, precision: precision, evaluatedAt: evaluatedAt})
+	}
+	rows.Close()
+	for _, hash := range outdated {
+		updateExpressionState(db, has_error, "expression is stored in outdated format", hash)
 	}
 	return expressions, nil
 }
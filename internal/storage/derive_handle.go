@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+	"github.com/XJIeI5/calculator/internal/parser"
+)
+
+// handleDerive returns derivative of expression by variable. if point "at" is set, derivative is
+// calculated at it like expression added by /add_expr and its id is returned too
+func (s *storage) handleDerive(w http.ResponseWriter, r *http.Request) {
+	if t := r.Header.Get("Content-Type"); t != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	_expr := struct {
+		Value     string             `json:"expr"`
+		Var       string             `json:"var"`
+		At        *float64           `json:"at"`
+		Vars      map[string]float64 `json:"vars"`
+		Mode      string             `json:"mode"`
+		Precision string             `json:"precision"`
+	}{Mode: "strict", Precision: "float64"}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&_expr); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := checkName(_expr.Var); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for name := range _expr.Vars {
+		if err := checkName(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	mode, ok := parser.ModeByName(_expr.Mode)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown parse mode '%s'", _expr.Mode), http.StatusBadRequest)
+		return
+	}
+
+	precision, err := op.ParsePrecision(_expr.Precision)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tree, err := parser.ParseMode(_expr.Value, mode)
+	if err != nil {
+		writeParseError(w, err)
+		return
+	}
+	derivative, err := parser.Derive(tree, _expr.Var)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := struct {
+		Derivative string `json:"derivative"`
+		// Id is id of derivative calculated at point, its result is returned by /get_result
+		Id int64 `json:"id,omitempty"`
+	}{Derivative: derivative.Infix()}
+
+	if _expr.At != nil {
+		bearerToken := r.Header.Get("Authorization")
+		if bearerToken == "" {
+			http.Error(w, "unknown user", http.StatusBadRequest)
+			return
+		}
+		if len(s.computationServers) == 0 {
+			http.Error(w, "no computation servers registered", http.StatusBadRequest)
+			return
+		}
+		userId, err := getUserId(bearerToken)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		vars := map[string]float64{_expr.Var: *_expr.At}
+		for name, v := range _expr.Vars {
+			if name != _expr.Var {
+				vars[name] = v
+			}
+		}
+		// names of derivative are taken from expression, so errors of names are shown in it
+		tokens, err := resolveNames(s.db, derivative.RPN(), userId, vars, _expr.Value)
+		if err != nil {
+			writeParseError(w, err)
+			return
+		}
+		if res.Id, err = s.enqueueExpression(rpnExpr(tokens), userId, precision, bearerToken); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+
+	data, err := json.Marshal(res)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(data)
+}
@@ -3,15 +3,16 @@ package storage
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"strconv"
-	"unicode"
+	"strings"
+	"time"
 
 	op "github.com/XJIeI5/calculator/internal/operation"
 	"github.com/XJIeI5/calculator/internal/parser"
-	"github.com/informitas/stack"
 )
 
 func (s *storage) handleAddExpression(w http.ResponseWriter, r *http.Request) {
@@ -29,8 +30,11 @@ func (s *storage) handleAddExpression(w http.ResponseWriter, r *http.Request) {
 	}
 
 	_expr := struct {
-		Value string `json:"expr"`
-	}{}
+		Value     string             `json:"expr"`
+		Vars      map[string]float64 `json:"vars"`
+		Mode      string             `json:"mode"`
+		Precision string             `json:"precision"`
+	}{Mode: "strict", Precision: "float64"}
 
 	decoder := json.NewDecoder(r.Body)
 	decoder.DisallowUnknownFields()
@@ -41,34 +45,91 @@ func (s *storage) handleAddExpression(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	parsedExpr, err := parser.ParseToPostfix(_expr.Value)
+	for name := range _expr.Vars {
+		if err := checkName(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	mode, ok := parser.ModeByName(_expr.Mode)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown parse mode '%s'", _expr.Mode), http.StatusBadRequest)
+		return
+	}
+
+	precision, err := op.ParsePrecision(_expr.Precision)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	hash := getHash(parsedExpr)
+	tree, err := parser.ParseMode(_expr.Value, mode)
+	if err != nil {
+		writeParseError(w, err)
+		return
+	}
 	bearerToken := r.Header.Get("Authorization")
+	userId, err := getUserId(bearerToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// names are resolved before hashing, so the same expression with other
+	// variables or changed constants isn't deduplicated with the old one
+	tokens, err := resolveNames(s.db, tree.RPN(), userId, _expr.Vars, _expr.Value)
+	if err != nil {
+		writeParseError(w, err)
+		return
+	}
+	id, err := s.enqueueExpression(rpnExpr(tokens), userId, precision, bearerToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Write([]byte(strconv.FormatInt(id, 10)))
+}
+
+// enqueueExpression stores expression and puts it to the queue of calculation, it returns id of expression.
+// expression is simplified before hashing, so "2 + x" and "x + 2" are the same expression
+// and constant parts aren't sent to computation servers. expression which was added by user
+// before isn't calculated again
+func (s *storage) enqueueExpression(rpn rpnExpr, userId int, precision op.Precision, bearerToken string) (int64, error) {
+	tree, err := parser.FromRPN(rpn)
+	if err != nil {
+		return 0, err
+	}
+	rpn = rpnExpr(parser.Simplify(tree, precision).RPN())
+	// now() is calculated as the time expression is added at, it is stored to calculate it the same way after restart
+	evaluatedAt := time.Now().UTC().Truncate(time.Second)
+	hash := getExprHash(rpn, precision, evaluatedAt)
 
 	if id, err := checkExpressionExists(s.db, hash, bearerToken); err == nil {
-		w.Write([]byte(strconv.FormatInt(id, 10)))
 		fmt.Println("again")
-		return
+		return id, nil
 	}
-	go func() {
-		userId, err := getUserId(bearerToken)
-		if err != nil {
-			panic(err)
-		}
-		s.exprQueue.Enqueue(expr{postfixExpr: postfixExpr(parsedExpr), userId: userId})
-	}()
+	go s.exprQueue.Enqueue(expr{rpnExpr: rpn, hash: hash, userId: userId, precision: precision, evaluatedAt: evaluatedAt})
 
-	id, err := storeExpressionState(s.db, in_progress, nil, bearerToken, postfixExpr(parsedExpr))
+	return storeExpressionState(s.db, in_progress, nil, bearerToken, rpn, hash, precision, evaluatedAt)
+}
+
+// writeParseError writes syntax error as json {"error": {...}} so the place of mistake can be shown
+func writeParseError(w http.ResponseWriter, err error) {
+	var syntaxErr *parser.SyntaxError
+	if !errors.As(err, &syntaxErr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	data, err := json.Marshal(struct {
+		Error *parser.SyntaxError `json:"error"`
+	}{Error: syntaxErr})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	w.Write([]byte(strconv.FormatInt(int64(id), 10)))
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	w.Write(data)
 }
 
 func (s *storage) handleGetResult(w http.ResponseWriter, r *http.Request) {
@@ -100,7 +161,7 @@ func (s *storage) calcExpressions() {
 		}
 		// waiting for expression then start calculation
 		go func() {
-			hashSum := getHash(string(_expr.postfixExpr))
+			hashSum := _expr.hash
 			compAddr, err := s.getMostFreeComputationServer()
 			if err != nil {
 				updateExpressionState(s.db, has_error, err.Error(), hashSum)
@@ -108,96 +169,226 @@ func (s *storage) calcExpressions() {
 				return
 			}
 			fmt.Println(compAddr)
-			errs, res := s.calculateInSync(compAddr, _expr.postfixExpr, _expr.userId)
-			for err := range errs {
-				if err != nil {
+			tree, err := parser.FromRPN(_expr.rpnExpr)
+			if err != nil {
+				updateExpressionState(s.db, has_error, err.Error(), hashSum)
+				return
+			}
+			result, err := s.calculateInSync(compAddr, tree, _expr)
+			if err != nil {
+				updateExpressionState(s.db, has_error, err.Error(), hashSum)
+				return
+			}
+			stored, err := result.stored(_expr.precision)
+			if err != nil {
+				updateExpressionState(s.db, has_error, err.Error(), hashSum)
+				return
+			}
+			if result.estimate != "" {
+				if err := updateExpressionEstimate(s.db, string(result.estimate), hashSum); err != nil {
 					updateExpressionState(s.db, has_error, err.Error(), hashSum)
 					return
 				}
 			}
-
-			result := <-res
-			updateExpressionState(s.db, ok, result, hashSum)
+			updateExpressionState(s.db, ok, stored, hashSum)
 		}()
 	}
 }
 
-func (s *storage) calculateInSync(addrCompServer string, expr postfixExpr, userId int) (<-chan error, <-chan float32) {
-	errs := make(chan error)
-	out := make(chan float32, 1)
-	go func(expr string) {
-		defer close(errs)
-		defer close(out)
-		locals := stack.NewStack[<-chan float32]()
-		var skip int
-		for i, r := range expr {
-			if skip > 0 {
-				skip--
-				continue
-			}
-			if r == ' ' {
-				continue
+// value is calculated value of expression tree node, booleans are calculated as 1 and 0.
+// estimate is error estimate of value calculated from integrals, it is empty for exact values
+type value struct {
+	number   op.Number
+	boolean  bool
+	estimate op.Number
+}
+
+// stored returns value as it is written to expressions table: "true" and "false" for booleans,
+// fraction for rational precision and decimal string for others. elements of matrices are written the same way
+func (v value) stored(precision op.Precision) (string, error) {
+	if v.boolean {
+		isTrue, err := v.number.IsTrue()
+		return strconv.FormatBool(isTrue), err
+	}
+	if precision.Kind == op.Rational && v.number.IsMatrix() {
+		return string(v.number), nil
+	}
+	if precision.Kind == op.Rational {
+		r, err := v.number.Rat()
+		if err != nil {
+			return "", err
+		}
+		return r.RatString(), nil
+	}
+	return precision.Decimal(v.number)
+}
+
+// calculateInSync calculates expression tree from leaves to root. conditional operands calculate
+// only the chosen arg, so "x != 0 && 1 / x > 2" doesn't send division by zero to computation server.
+// now() is the time expression was added at, so recalculated expression has the same result
+func (s *storage) calculateInSync(addrCompServer string, node *parser.Node, e expr) (value, error) {
+	if v, ok := e.vars[node.Text]; ok && node.Kind == parser.NameToken {
+		return value{number: v}, nil
+	}
+	if node.Kind == parser.NumberToken || node.Kind == parser.NameToken {
+		v, err := node.Decimal()
+		return value{number: v}, err
+	}
+
+	operand, err := node.Operand()
+	if err != nil {
+		return value{}, err
+	}
+	_, isBoolean := operand.(op.BooleanOperand)
+
+	if clock, ok := operand.(op.ClockOperand); ok {
+		return value{number: clock.At(e.evaluatedAt)}, nil
+	}
+
+	if _, ok := node.BoundVariable(); ok {
+		switch operand {
+		case op.Solve:
+			res, err := s.solve(addrCompServer, node, e)
+			return value{number: res}, err
+		case op.Integrate:
+			res, estimate, err := s.integrate(addrCompServer, node, e)
+			return value{number: res, estimate: estimate}, err
+		case op.Sum:
+			res, estimate, err := s.summation(addrCompServer, node, e)
+			return value{number: res, estimate: estimate}, err
+		}
+	}
+
+	if cond, ok := operand.(op.ConditionalOperand); ok {
+		res, err := s.calculateInSync(addrCompServer, node.Args[0], e)
+		if err != nil {
+			return value{}, err
+		}
+		isTrue, err := res.number.IsTrue()
+		if err != nil {
+			return value{}, err
+		}
+		if i := cond.Choose(isTrue); i != 0 {
+			res, err = s.calculateInSync(addrCompServer, node.Args[i], e)
+			if err != nil {
+				return value{}, err
 			}
-			if unicode.IsDigit(r) {
-				num := parser.GetStringNumber(expr[i:])
-				v, _ := strconv.ParseFloat(num, 32)
-				skip = len(num) - 1
-
-				ch := make(chan float32, 1)
-				ch <- float32(v)
-				locals.Push(ch)
-			} else {
-				operand := parser.GetOperand(expr[i:])
-				if operand == nil {
-					errs <- fmt.Errorf("unknown operand")
-					return
-				}
-				skip = len(operand.Symbol()) - 1
-				duration, err := getOperandTime(s.db, string(r), userId)
-				if err == nil {
-					second, _ := locals.Pop()
-					first, _ := locals.Pop()
-					info := op.BinaryOperationInfo{A: <-first, B: <-second, Op: operand.Symbol()}
-					res, err := calculateBinary(addrCompServer, int(duration.Milliseconds()), info)
-					if err != nil {
-						errs <- err
-						return
-					}
-					ch := make(chan float32, 1)
-					ch <- res
-					locals.Push(ch)
-				} else {
-					errs <- fmt.Errorf("no timeout for '%c'", r)
-					return
-				}
+		}
+		if isBoolean {
+			if isTrue, err = res.number.IsTrue(); err != nil {
+				return value{}, err
 			}
+			res = value{number: op.FloatNumber(op.FromBool(isTrue)), boolean: true}
+		}
+		return res, nil
+	}
+
+	args := make([]op.Number, len(node.Args))
+	estimates := make([]op.Number, len(node.Args))
+	for i, arg := range node.Args {
+		v, err := s.calculateInSync(addrCompServer, arg, e)
+		if err != nil {
+			return value{}, err
 		}
-		res, _ := locals.Pop()
-		out <- <-res
-	}(string(expr))
-	return errs, out
+		args[i], estimates[i] = v.number, v.estimate
+	}
+	var estimate op.Number
+	if !isBoolean {
+		estimate = propagateEstimate(operand, args, estimates)
+	}
+	duration, err := getOperandTime(s.db, operand.Symbol(), e.userId)
+	if err != nil {
+		return value{}, fmt.Errorf("no timeout for '%s'", operand.Symbol())
+	}
+	if fn, ok := operand.(op.AggregateOperand); ok {
+		res, err := s.aggregate(addrCompServer, int(duration.Milliseconds()), fn, args, e.precision)
+		return value{number: res, estimate: estimate}, err
+	}
+	if operand == op.Mult && args[0].IsMatrix() && args[1].IsMatrix() {
+		res, err := s.multiplyMatrices(addrCompServer, int(duration.Milliseconds()), args[0], args[1], e.precision)
+		return value{number: res, estimate: estimate}, err
+	}
+	var res op.Number
+	switch operand.(type) {
+	case op.FunctionOperand:
+		info := op.FunctionOperationInfo{Args: args, Op: operand.Symbol(), Precision: e.precision}
+		res, err = calculateFunction(addrCompServer, int(duration.Milliseconds()), info)
+	case op.UnaryOperand:
+		info := op.UnaryOperationInfo{A: args[0], Op: operand.Symbol(), Precision: e.precision}
+		res, err = calculateUnary(addrCompServer, int(duration.Milliseconds()), info)
+	default:
+		info := op.BinaryOperationInfo{A: args[0], B: args[1], Op: operand.Symbol(), Precision: e.precision}
+		res, err = calculateBinary(addrCompServer, int(duration.Milliseconds()), info)
+	}
+	if err != nil {
+		return value{}, err
+	}
+	return value{number: res, boolean: isBoolean, estimate: estimate}, nil
 }
 
-func calculateBinary(addrComp string, dur int, binInfo op.BinaryOperationInfo) (float32, error) {
+func calculateBinary(addrComp string, dur int, binInfo op.BinaryOperationInfo) (op.Number, error) {
 	data := struct {
 		Dur                    int `json:"duration"`
 		op.BinaryOperationInfo `json:"op_info"`
 	}{Dur: dur, BinaryOperationInfo: binInfo}
+	return postOperation(fmt.Sprintf("%s/%s", addrComp, "exec"), data)
+}
+
+func calculateUnary(addrComp string, dur int, unInfo op.UnaryOperationInfo) (op.Number, error) {
+	data := struct {
+		Dur                   int `json:"duration"`
+		op.UnaryOperationInfo `json:"op_info"`
+	}{Dur: dur, UnaryOperationInfo: unInfo}
+	return postOperation(fmt.Sprintf("%s/%s", addrComp, "exec_unary"), data)
+}
+
+func calculateFunction(addrComp string, dur int, funcInfo op.FunctionOperationInfo) (op.Number, error) {
+	data := struct {
+		Dur                      int `json:"duration"`
+		op.FunctionOperationInfo `json:"op_info"`
+	}{Dur: dur, FunctionOperationInfo: funcInfo}
+	return postOperation(fmt.Sprintf("%s/%s", addrComp, "exec_func"), data)
+}
+
+// postOperation sends operation to computation server and returns its result
+func postOperation(url string, data interface{}) (op.Number, error) {
 	byteData, err := json.Marshal(data)
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 
-	resp, err := http.Post(fmt.Sprintf("%s/%s", addrComp, "exec"), "application/json", bytes.NewBuffer(byteData))
+	resp, err := http.Post(url, "application/json", bytes.NewBuffer(byteData))
 	if err != nil {
-		return 0, err
+		return "", err
 	}
 	defer resp.Body.Close()
 	res, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return 0, err
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", &computeError{url: url, status: resp.StatusCode, message: strings.TrimSpace(string(res))}
 	}
+	return op.Number(strings.TrimSpace(string(res))), nil
+}
+
+// computeError is an answer of computation server which isn't a result: error of operation
+// or status without message, which is returned by busy server
+type computeError struct {
+	url     string
+	status  int
+	message string
+}
+
+func (e *computeError) Error() string {
+	if e.message != "" {
+		return e.message
+	}
+	return fmt.Sprintf("computation server %s answered %d %s", e.url, e.status, http.StatusText(e.status))
+}
 
-	value, err := strconv.ParseFloat(string(res), 32)
-	return float32(value), err
+// isServerBusy returns true if operation wasn't calculated because computation server has no free processes
+func isServerBusy(err error) bool {
+	var computeErr *computeError
+	return errors.As(err, &computeErr) && computeErr.status == http.StatusInternalServerError
 }
@@ -34,13 +34,12 @@ func (s *storage) handleRegister(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		panic(err)
 	}
-	timeouts := map[string]int{"+": 500, "*": 500, "/": 500, "-": 500}
-	for operand, value := range timeouts {
-		userId, err := res.LastInsertId()
-		if err != nil {
-			panic(err)
-		}
-		storeTimeout(s.db, operand, value, int(userId))
+	userId, err := res.LastInsertId()
+	if err != nil {
+		panic(err)
+	}
+	if err := storeMissingTimeouts(s.db, int(userId)); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 	}
 }
 
@@ -0,0 +1,46 @@
+package storage
+
+import (
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+// matrixFanOutSize is the least amount of multiplications of elements in product of matrices,
+// which is split into rows calculated by free processes of all working computation servers
+const matrixFanOutSize = 64
+
+// multiplyMatrices returns product of matrices a and b. small product is sent to addrCompServer,
+// big one is split by rows of a: row i of product is row i of a multiplied by b
+func (s *storage) multiplyMatrices(addrCompServer string, dur int, a, b op.Number, precision op.Precision) (op.Number, error) {
+	info := op.BinaryOperationInfo{A: a, B: b, Op: op.Mult.Symbol(), Precision: precision}
+	left, err := a.Matrix()
+	if err != nil {
+		return "", err
+	}
+	right, err := b.Matrix()
+	if err != nil {
+		return "", err
+	}
+	rows, cols := left.Size()
+	_, rightCols := right.Size()
+	if rows == 1 || rows*cols*rightCols < matrixFanOutSize {
+		return calculateBinary(addrCompServer, dur, info)
+	}
+
+	results, err := s.fanOut(addrCompServer, rows, func(addr string, i int) (op.Number, error) {
+		rowInfo := info
+		rowInfo.A = op.MatrixNumber(op.Matrix{left[i]})
+		return calculateBinary(addr, dur, rowInfo)
+	})
+	if err != nil {
+		return "", err
+	}
+	product := make(op.Matrix, rows)
+	for i, res := range results {
+		row, err := res.Matrix()
+		if err != nil {
+			return "", err
+		}
+		product[i] = row[0]
+	}
+	return op.MatrixNumber(product), nil
+}
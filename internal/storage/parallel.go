@@ -0,0 +1,57 @@
+package storage
+
+import (
+	"sync"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+// fanOut calculates n independent parts of operation: calc sends part i to computation server addr.
+// every working computation server calculates as many parts at once as it has free processes,
+// if there are no free processes, parts are sent to addrCompServer one by one.
+// part which isn't calculated because server became busy is sent to other servers.
+// results are in order of parts, the first error is returned
+func (s *storage) fanOut(addrCompServer string, n int, calc func(addr string, i int) (op.Number, error)) ([]op.Number, error) {
+	workers := s.getFreeProcesses()
+	if len(workers) == 0 {
+		workers = map[string]int{addrCompServer: 1}
+	}
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+		indexes  = make(chan int, n)
+		results  = make([]op.Number, n)
+	)
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	for addr, free := range workers {
+		for w := 0; w < free; w++ {
+			wg.Add(1)
+			go func(addr string) {
+				defer wg.Done()
+				for i := range indexes {
+					res, err := calc(addr, i)
+					for other := range workers {
+						if !isServerBusy(err) {
+							break
+						}
+						if other != addr {
+							res, err = calc(other, i)
+						}
+					}
+					mu.Lock()
+					if err != nil && firstErr == nil {
+						firstErr = err
+					}
+					results[i] = res
+					mu.Unlock()
+				}
+			}(addr)
+		}
+	}
+	wg.Wait()
+	return results, firstErr
+}
@@ -0,0 +1,59 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/XJIeI5/calculator/internal/computation"
+	op "github.com/XJIeI5/calculator/internal/operation"
+)
+
+// newBusyServer returns computation server which says it has free processes, but answers 500 to operations
+func newBusyServer() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/free_process" {
+			w.Write([]byte("4"))
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+}
+
+func newComputeServer() *httptest.Server {
+	return httptest.NewServer(computation.GetServer("http://localhost", 0, 4).Handler)
+}
+
+func TestFanOutRetriesBusyServer(t *testing.T) {
+	busy, working := newBusyServer(), newComputeServer()
+	defer busy.Close()
+	defer working.Close()
+	s := &storage{computationServers: map[string]int64{busy.URL: 0, working.URL: 0}}
+
+	res, err := s.fanOut(busy.URL, 10, func(addr string, i int) (op.Number, error) {
+		info := op.BinaryOperationInfo{A: op.FloatNumber(float64(i)), B: "1", Op: op.Add.Symbol()}
+		return calculateBinary(addr, 0, info)
+	})
+	if err != nil {
+		t.Fatalf("error got '%s'", err)
+	}
+	for i, v := range res {
+		if expected := op.FloatNumber(float64(i + 1)); v != expected {
+			t.Errorf("part %d is not '%s', got '%s'", i, expected, v)
+		}
+	}
+}
+
+func TestFanOutBusyError(t *testing.T) {
+	busy := newBusyServer()
+	defer busy.Close()
+	s := &storage{computationServers: map[string]int64{busy.URL: 0}}
+
+	_, err := s.fanOut(busy.URL, 2, func(addr string, i int) (op.Number, error) {
+		return calculateBinary(addr, 0, op.BinaryOperationInfo{A: "1", B: "1", Op: op.Add.Symbol()})
+	})
+	if err == nil || !strings.Contains(err.Error(), busy.URL) || !strings.Contains(err.Error(), "500") {
+		t.Errorf("error doesn't have address and status of server, got '%v'", err)
+	}
+}
@@ -0,0 +1,215 @@
+package storage
+
+import (
+	"fmt"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+	"github.com/XJIeI5/calculator/internal/parser"
+)
+
+// solveProgress is the last step of solve, it is returned by /get_result while solve is calculated
+type solveProgress struct {
+	Method    string `json:"method"`
+	Iteration int    `json:"iteration"`
+	X         string `json:"x"`
+	// Residual is value of equation at x, it is 0 at root
+	Residual string `json:"residual"`
+}
+
+// solver finds root of equation f(x) = 0, f is calculated by computation servers
+type solver struct {
+	s          *storage
+	f          *parser.Node
+	variable   string
+	tolerance  op.Number
+	iterations int
+	e          expr
+}
+
+// solve finds root of solve(equation, variable, start[, tolerance[, iterations]]). start number is the
+// first approximation of Newton's method, start interval is bisected until root is found. root of "a = b"
+// is root of "a - b". every iteration is stored as progress of expression
+func (s *storage) solve(addrCompServer string, node *parser.Node, e expr) (op.Number, error) {
+	sv := solver{s: s, f: node.Args[0], variable: node.Args[1].Text, e: e,
+		tolerance: op.FloatNumber(op.DefaultSolveTolerance), iterations: op.DefaultSolveIterations}
+	if operand, _ := sv.f.Operand(); operand == op.Equation {
+		sv.f = &parser.Node{Token: parser.Token{Kind: parser.OperandToken, Text: op.Sub.Symbol()}, Args: sv.f.Args}
+	}
+
+	args, err := s.boundArgs(addrCompServer, node, e)
+	if err != nil {
+		return "", err
+	}
+	if len(args) > 1 {
+		if sign, err := sv.sign(args[1]); err != nil || sign <= 0 {
+			return "", fmt.Errorf("tolerance of solve must be a positive number, got '%s'", args[1])
+		}
+		sv.tolerance = args[1]
+	}
+	if len(args) > 2 {
+		iterations, err := args[2].Rat()
+		if err != nil || !iterations.IsInt() || iterations.Sign() <= 0 || !iterations.Num().IsInt64() {
+			return "", fmt.Errorf("amount of iterations of solve must be a positive integer, got '%s'", args[2])
+		}
+		sv.iterations = int(iterations.Num().Int64())
+	}
+
+	if args[0].IsInterval() {
+		iv, err := args[0].Interval()
+		if err != nil {
+			return "", err
+		}
+		return sv.bisection(addrCompServer, op.FloatNumber(iv.Lo), op.FloatNumber(iv.Hi))
+	}
+	return sv.newton(addrCompServer, args[0])
+}
+
+// newton returns root found by Newton's method: x is x - f(x)/f'(x) until step is less than tolerance.
+// f and its derivative are calculated at once by different computation servers
+func (sv solver) newton(addrCompServer string, x op.Number) (op.Number, error) {
+	derivative, err := parser.Derive(sv.f, sv.variable)
+	if err != nil {
+		return "", fmt.Errorf("%w, write interval with root as start of solve to use bisection", err)
+	}
+	p := sv.e.precision
+	for i := 1; i <= sv.iterations; i++ {
+		trees := []*parser.Node{sv.f, derivative}
+		values, err := sv.s.fanOut(addrCompServer, len(trees), func(addr string, j int) (op.Number, error) {
+			return sv.at(addr, trees[j], x)
+		})
+		if err != nil {
+			return "", err
+		}
+		fx, dfx := values[0], values[1]
+		if err := sv.report("newton", i, x, fx); err != nil {
+			return "", err
+		}
+		if isZero, err := isZero(fx); err != nil || isZero {
+			return x, err
+		}
+		if isZero, err := isZero(dfx); err != nil || isZero {
+			if err == nil {
+				err = fmt.Errorf("derivative is 0 at %s, write interval with root as start of solve to use bisection", x)
+			}
+			return "", err
+		}
+		step, err := p.ExecBinary(op.Div, fx, dfx)
+		if err != nil {
+			return "", err
+		}
+		if x, err = p.ExecBinary(op.Sub, x, step); err != nil {
+			return "", err
+		}
+		if small, err := sv.lessThanTolerance(step); err != nil || small {
+			return x, err
+		}
+	}
+	return "", fmt.Errorf("solve didn't find root in %d iterations", sv.iterations)
+}
+
+// bisection returns root found by halving interval [lo, hi], f must have different signs at its ends
+func (sv solver) bisection(addrCompServer string, lo, hi op.Number) (op.Number, error) {
+	p := sv.e.precision
+	ends, err := sv.s.fanOut(addrCompServer, 2, func(addr string, j int) (op.Number, error) {
+		return sv.at(addr, sv.f, []op.Number{lo, hi}[j])
+	})
+	if err != nil {
+		return "", err
+	}
+	loSign, err := sv.sign(ends[0])
+	if err != nil || loSign == 0 {
+		return lo, err
+	}
+	hiSign, err := sv.sign(ends[1])
+	if err != nil || hiSign == 0 {
+		return hi, err
+	}
+	if loSign == hiSign {
+		return "", fmt.Errorf("equation has the same sign at both ends of [%s, %s]", lo, hi)
+	}
+	for i := 1; i <= sv.iterations; i++ {
+		sum, err := p.ExecBinary(op.Add, lo, hi)
+		if err != nil {
+			return "", err
+		}
+		mid, err := p.ExecBinary(op.Div, sum, "2")
+		if err != nil {
+			return "", err
+		}
+		fmid, err := sv.at(addrCompServer, sv.f, mid)
+		if err != nil {
+			return "", err
+		}
+		if err := sv.report("bisection", i, mid, fmid); err != nil {
+			return "", err
+		}
+		midSign, err := sv.sign(fmid)
+		if err != nil || midSign == 0 {
+			return mid, err
+		}
+		if midSign == loSign {
+			lo = mid
+		} else {
+			hi = mid
+		}
+		// root is in [lo, hi], so mid is closer to it than width of interval
+		width, err := p.ExecBinary(op.Sub, hi, lo)
+		if err != nil {
+			return "", err
+		}
+		if small, err := sv.lessThanTolerance(width); err != nil || small {
+			return mid, err
+		}
+	}
+	return "", fmt.Errorf("solve didn't find root in %d iterations", sv.iterations)
+}
+
+// at calculates tree with value x of variable
+func (sv solver) at(addrCompServer string, tree *parser.Node, x op.Number) (op.Number, error) {
+	res, err := sv.s.calculateAt(addrCompServer, tree, sv.e, sv.variable, x)
+	return res.number, err
+}
+
+// report stores iteration as progress of expression
+func (sv solver) report(method string, iteration int, x, fx op.Number) error {
+	progress := solveProgress{Method: method, Iteration: iteration, X: string(x), Residual: string(fx)}
+	if decimal, err := sv.e.precision.Decimal(x); err == nil {
+		progress.X = decimal
+	}
+	if decimal, err := sv.e.precision.Decimal(fx); err == nil {
+		progress.Residual = decimal
+	}
+	return updateExpressionProgress(sv.s.db, progress, sv.e.hash)
+}
+
+func isZero(v op.Number) (bool, error) {
+	isTrue, err := v.IsTrue()
+	return !isTrue, err
+}
+
+// sign returns -1, 0 or 1 by sign of v
+func (sv solver) sign(v op.Number) (int, error) {
+	for sign, o := range map[int]op.BinaryOperand{-1: op.Less, 1: op.Greater} {
+		res, err := sv.e.precision.ExecBinary(o, v, "0")
+		if err != nil {
+			return 0, err
+		}
+		if isTrue, err := res.IsTrue(); err != nil || isTrue {
+			return sign, err
+		}
+	}
+	return 0, nil
+}
+
+// lessThanTolerance returns true if absolute value of v isn't more than tolerance
+func (sv solver) lessThanTolerance(v op.Number) (bool, error) {
+	abs, err := sv.e.precision.ExecFunction(op.Abs, v)
+	if err != nil {
+		return false, err
+	}
+	res, err := sv.e.precision.ExecBinary(op.LessEqual, abs, sv.tolerance)
+	if err != nil {
+		return false, err
+	}
+	return res.IsTrue()
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"crypto/sha1"
 	"database/sql"
+	"database/sql/driver"
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
@@ -11,8 +12,11 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"time"
 
 	datastructs "github.com/XJIeI5/calculator/internal/datastructs"
+	op "github.com/XJIeI5/calculator/internal/operation"
+	"github.com/XJIeI5/calculator/internal/parser"
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
 )
@@ -63,6 +67,16 @@ func newStorage(db *sql.DB, addr string) *storage {
 		exprQueue:          datastructs.NewQueue[expr](10),
 	}
 	storeTimeout(s.db, "wait", 10000, 0)
+	// users registered before new operands were added don't have timeouts for them
+	userIds, err := getUserIds(db)
+	if err != nil && err != sql.ErrNoRows {
+		panic(err)
+	}
+	for _, id := range userIds {
+		if err := storeMissingTimeouts(db, id); err != nil {
+			panic(err)
+		}
+	}
 
 	// set not done expressions queue
 	for _, expr := range expressions {
@@ -88,12 +102,18 @@ func newStorage(db *sql.DB, addr string) *storage {
 	// expr handle
 	r.HandleFunc("/add_expr", s.handleAddExpression).Methods("POST")
 	r.HandleFunc("/get_result", s.handleGetResult).Methods("GET")
+	r.HandleFunc("/derive", s.handleDerive).Methods("POST")
+	r.HandleFunc("/tabulate", s.handleTabulate).Methods("POST")
 	// compute handle
 	r.HandleFunc("/regist_compute", s.handleRegistCompute).Methods("POST")
 	r.HandleFunc("/heart", s.handleHeartbeat).Methods("POST")
 	r.HandleFunc("/get_compute", s.handleGetCompute).Methods("GET")
 	// timeout handle
 	r.HandleFunc("/set_timeout", s.handleSetTimeouts).Methods("POST")
+	// constant handle
+	r.HandleFunc("/set_const", s.handleSetConstants).Methods("POST")
+	r.HandleFunc("/get_const", s.handleGetConstants).Methods("GET")
+	r.HandleFunc("/delete_const", s.handleDeleteConstants).Methods("POST")
 	// login
 	r.HandleFunc("/regist_user", s.handleRegister).Methods("POST")
 	r.HandleFunc("/login", s.handleLogin).Methods("POST")
@@ -121,15 +141,60 @@ func GetServer(addr string, port int, db *sql.DB) *http.Server {
 }
 
 type state string
-type postfixExpr string
 type exprHash int
 
+// rpnExpr is an expression in reverse polish notation, stored in db as json
+type rpnExpr []parser.Token
+
+func (e rpnExpr) Value() (driver.Value, error) {
+	data, err := json.Marshal(e)
+	return string(data), err
+}
+
+func (e *rpnExpr) Scan(src interface{}) error {
+	var data []byte
+	switch t := src.(type) {
+	case string:
+		data = []byte(t)
+	case []byte:
+		data = t
+	default:
+		return fmt.Errorf("can't scan %T into expression", src)
+	}
+	return json.Unmarshal(data, e)
+}
+
 func getHash(line string) exprHash {
 	h := sha1.New()
 	h.Write([]byte(line))
 	return exprHash(binary.BigEndian.Uint32(h.Sum(nil)))
 }
 
+// getExprHash returns hash of expression, the same expression with other precision has other hash.
+// expression with now() is the same expression only if it is added at the same time
+func getExprHash(e rpnExpr, precision op.Precision, addedAt time.Time) exprHash {
+	line := parser.FormatRPN(e)
+	if precision != (op.Precision{}) {
+		line += precision.String()
+	}
+	if usesClock(e) {
+		line += addedAt.Format(time.RFC3339)
+	}
+	return getHash(line)
+}
+
+// usesClock returns true if expression has operand which is calculated from the time expression is added at
+func usesClock(rpn rpnExpr) bool {
+	for _, token := range rpn {
+		if operand, err := token.Operand(); err == nil {
+			if _, ok := operand.(op.ClockOperand); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 const (
 	_           state = ""
 	has_error   state = "error"
@@ -141,12 +206,53 @@ var (
 	key []byte
 )
 
+// defaultOperandTimeout is a timeout in milliseconds of operand user didn't set timeout for
+const defaultOperandTimeout = 500
+
 type expressionState struct {
 	State  state       `json:"state"`
 	Result interface{} `json:"result"`
+	// Fraction is exact result of expression calculated with rational precision: "1/2"
+	Fraction string `json:"fraction,omitempty"`
+	// Complex is real and imaginary parts of complex result: "1+2i"
+	Complex *complexResult `json:"complex,omitempty"`
+	// Interval is bounds of interval result: "[5.7,6.3]"
+	Interval *intervalResult `json:"interval,omitempty"`
+	// Quantity is value and unit of result with unit: "10 N"
+	Quantity *quantityResult `json:"quantity,omitempty"`
+	// Matrix is elements of matrix result by rows: [["1","2"],["3","4"]]
+	Matrix op.Matrix `json:"matrix,omitempty"`
+	// EvaluatedAt is the time expression was added at, now() returns it
+	EvaluatedAt string `json:"evaluated_at,omitempty"`
+	// Progress is the last step of solve: {"method": "newton", "iteration": 3, "x": "2.09", "residual": "0.0001"}
+	Progress json.RawMessage `json:"progress,omitempty"`
+	// ErrorEstimate is estimated absolute error of result calculated from integrals: "1.2e-14",
+	// it is "unavailable" if result isn't linear function of integrals
+	ErrorEstimate string `json:"error_estimate,omitempty"`
+}
+
+type complexResult struct {
+	Real float64 `json:"re"`
+	Imag float64 `json:"im"`
+}
+
+type intervalResult struct {
+	Lo float64 `json:"lo"`
+	Hi float64 `json:"hi"`
+}
+
+type quantityResult struct {
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit"`
 }
 
 type expr struct {
-	postfixExpr
-	userId int
+	rpnExpr
+	hash      exprHash
+	userId    int
+	precision op.Precision
+	// evaluatedAt is the time expression was added at, it is the result of now()
+	evaluatedAt time.Time
+	// vars are values of variables of solve while its equation is calculated
+	vars map[string]op.Number
 }
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	op "github.com/XJIeI5/calculator/internal/operation"
+	"github.com/XJIeI5/calculator/internal/parser"
+)
+
+// maxTabulatePoints is the most amount of points of one table
+const maxTabulatePoints = 10000
+
+// tabulatePoint is value of function at x, value is empty if it can't be calculated at x
+type tabulatePoint struct {
+	X     float64 `json:"x"`
+	Y     string  `json:"y,omitempty"`
+	Error string  `json:"error,omitempty"`
+}
+
+// handleTabulate calculates function of variable at points from "from" to "to" with step "step".
+// points are calculated at once by free processes of all computation servers. table is returned
+// as json, csv or svg chart by field "format"
+func (s *storage) handleTabulate(w http.ResponseWriter, r *http.Request) {
+	if t := r.Header.Get("Content-Type"); t != "application/json" {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	bearerToken := r.Header.Get("Authorization")
+	if bearerToken == "" {
+		http.Error(w, "unknown user", http.StatusBadRequest)
+		return
+	}
+	if len(s.computationServers) == 0 {
+		http.Error(w, "no computation servers registered", http.StatusBadRequest)
+		return
+	}
+
+	_expr := struct {
+		Value     string             `json:"expr"`
+		Var       string             `json:"var"`
+		From      float64            `json:"from"`
+		To        float64            `json:"to"`
+		Step      float64            `json:"step"`
+		Vars      map[string]float64 `json:"vars"`
+		Mode      string             `json:"mode"`
+		Precision string             `json:"precision"`
+		Format    string             `json:"format"`
+	}{Var: "x", Mode: "strict", Precision: "float64", Format: "json"}
+
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&_expr); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := checkName(_expr.Var); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	for name := range _expr.Vars {
+		if err := checkName(name); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	switch _expr.Format {
+	case "json", "csv", "svg":
+	default:
+		http.Error(w, fmt.Sprintf("unknown format '%s'", _expr.Format), http.StatusBadRequest)
+		return
+	}
+	xs, err := samplePoints(_expr.From, _expr.To, _expr.Step)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mode, ok := parser.ModeByName(_expr.Mode)
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown parse mode '%s'", _expr.Mode), http.StatusBadRequest)
+		return
+	}
+
+	precision, err := op.ParsePrecision(_expr.Precision)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tree, err := parser.ParseMode(_expr.Value, mode)
+	if err != nil {
+		writeParseError(w, err)
+		return
+	}
+	userId, err := getUserId(bearerToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	tokens, err := resolveFreeNames(s.db, tree.RPN(), userId, _expr.Vars, map[string]bool{_expr.Var: true}, _expr.Value)
+	if err != nil {
+		writeParseError(w, err)
+		return
+	}
+	if tree, err = parser.FromRPN(tokens); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	tree = parser.Simplify(tree, precision)
+
+	compAddr, err := s.getMostFreeComputationServer()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	e := expr{userId: userId, precision: precision, evaluatedAt: time.Now().UTC().Truncate(time.Second)}
+	points := s.tabulate(compAddr, tree, _expr.Var, xs, e)
+
+	switch _expr.Format {
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv")
+		writer := csv.NewWriter(w)
+		writer.Write([]string{_expr.Var, _expr.Value})
+		for _, p := range points {
+			writer.Write([]string{strconv.FormatFloat(p.X, 'g', -1, 64), p.Y})
+		}
+		writer.Flush()
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		w.Write(renderChart(_expr.Value, points))
+	default:
+		data, err := json.Marshal(struct {
+			Var    string          `json:"var"`
+			Points []tabulatePoint `json:"points"`
+		}{Var: _expr.Var, Points: points})
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	}
+}
+
+// samplePoints returns points from "from" to "to" with step, "to" is included if it is on the step
+func samplePoints(from, to, step float64) ([]float64, error) {
+	if step <= 0 || math.IsInf(step, 0) || math.IsNaN(step) {
+		return nil, fmt.Errorf("step must be a positive number, got %g", step)
+	}
+	if to < from {
+		return nil, fmt.Errorf("range [%g, %g] is empty", from, to)
+	}
+	// small error of division doesn't lose the last point: 0.3 / 0.1 is 2.9999999999999996
+	n := math.Floor((to-from)/step+1e-9) + 1
+	if n > maxTabulatePoints {
+		return nil, fmt.Errorf("range has %g points, the most is %d", n, maxTabulatePoints)
+	}
+	xs := make([]float64, int(n))
+	for i := range xs {
+		// points are counted from the start, so errors of step aren't accumulated
+		xs[i] = from + float64(i)*step
+	}
+	return xs, nil
+}
+
+// tabulate calculates tree at every x by free processes of all computation servers.
+// error at one point doesn't stop calculation of others, it is written to the point
+func (s *storage) tabulate(addrCompServer string, tree *parser.Node, variable string, xs []float64, e expr) []tabulatePoint {
+	points := make([]tabulatePoint, len(xs))
+	s.fanOut(addrCompServer, len(xs), func(addr string, i int) (op.Number, error) {
+		pointExpr := e
+		pointExpr.vars = map[string]op.Number{variable: op.FloatNumber(xs[i])}
+		points[i].X = xs[i]
+		v, err := s.calculateInSync(addr, tree, pointExpr)
+		if err == nil {
+			points[i].Y, err = v.stored(e.precision)
+		}
+		if err != nil {
+			points[i].Y, points[i].Error = "", err.Error()
+		}
+		return "", nil
+	})
+	return points
+}
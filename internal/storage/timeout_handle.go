@@ -35,11 +35,13 @@ func (s *storage) handleSetTimeouts(w http.ResponseWriter, r *http.Request) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	for key, value := range timeouts.Value {
-		if !op.HaveOperand(key) {
+		operand, ok := op.FindOperand(key)
+		if !ok {
 			http.Error(w, fmt.Sprintf("there is no operand '%s'", key), http.StatusBadRequest)
 			return
 		}
-		err := storeTimeout(s.db, key, value, userId)
+		// aliases share timeout with the main symbol: "**" and "^"
+		err := storeTimeout(s.db, operand.Symbol(), value, userId)
 		if err != nil {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
 			return